@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// adminMSPIDs lists the organisations whose members may call admin-only functions
+var adminMSPIDs = []string{
+	"ElectionCommissionMSP",
+	"StateElectionOfficeMSP",
+}
+
+// requireAdmin returns an error unless the caller belongs to an admin organisation
+func requireAdmin(ctx contractapi.TransactionContextInterface) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read client identity: %v", err)
+	}
+	if !containsString(adminMSPIDs, mspID) {
+		return fmt.Errorf("access denied: %s is not an admin organisation", mspID)
+	}
+	return nil
+}
+
+// candidateIDAttribute is the client certificate attribute naming the
+// candidate a caller acts for
+const candidateIDAttribute = "candidateId"
+
+// requireCandidate returns an error unless the caller's certificate names the given candidate
+func requireCandidate(ctx contractapi.TransactionContextInterface, candidateID string) error {
+	err := ctx.GetClientIdentity().AssertAttributeValue(candidateIDAttribute, candidateID)
+	if err != nil {
+		return fmt.Errorf("access denied: caller does not act for candidate %s", candidateID)
+	}
+	return nil
+}
+
+// Observer is an accredited observer allowed to read restricted data
+type Observer struct {
+	ID           string    `json:"id"` // Client identity ID of the observer
+	MSPID        string    `json:"mspId"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// RegisterObserver accredits a client identity of the given organisation as
+// an observer. Admin only
+func (s *VotingContract) RegisterObserver(ctx contractapi.TransactionContextInterface, observerID string, mspID string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if observerID == "" || mspID == "" {
+		return fmt.Errorf("observer ID and MSP ID must not be empty")
+	}
+
+	observerKey := "OBSERVER_" + observerID
+	observerJSON, err := ctx.GetStub().GetState(observerKey)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if observerJSON != nil {
+		return fmt.Errorf("the observer %s already exists", observerID)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	observerJSON, err = json.Marshal(Observer{
+		ID:           observerID,
+		MSPID:        mspID,
+		RegisteredAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(observerKey, observerJSON)
+}
+
+// isObserver returns true when the caller's identity and organisation match a registered observer
+func isObserver(ctx contractapi.TransactionContextInterface) (bool, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return false, fmt.Errorf("failed to read client identity: %v", err)
+	}
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to read client identity: %v", err)
+	}
+
+	observerJSON, err := ctx.GetStub().GetState("OBSERVER_" + clientID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if observerJSON == nil {
+		return false, nil
+	}
+
+	var observer Observer
+	err = json.Unmarshal(observerJSON, &observer)
+	if err != nil {
+		return false, err
+	}
+
+	return observer.MSPID == mspID, nil
+}
+
+// requireAdminOrObserver returns an error unless the caller belongs to an
+// admin organisation or is a registered observer
+func requireAdminOrObserver(ctx contractapi.TransactionContextInterface) error {
+	if requireAdmin(ctx) == nil {
+		return nil
+	}
+
+	observer, err := isObserver(ctx)
+	if err != nil {
+		return err
+	}
+	if !observer {
+		return fmt.Errorf("access denied: caller is neither an admin nor a registered observer")
+	}
+	return nil
+}
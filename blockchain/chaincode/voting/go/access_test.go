@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		mspID   string
+		wantErr bool
+	}{
+		{"ElectionCommissionMSP", false},
+		{"StateElectionOfficeMSP", false},
+		{"Org1MSP", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mspID, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.asIdentity("client", tt.mspID, nil)
+			err := requireAdmin(env.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("requireAdmin error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterObserver(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+
+	must(t, env.contract.RegisterObserver(env.ctx, "observer1", "ObserverMSP"))
+	mustFail(t, env.contract.RegisterObserver(env.ctx, "observer1", "ObserverMSP"), "already exists")
+	mustFail(t, env.contract.RegisterObserver(env.ctx, "", "ObserverMSP"), "must not be empty")
+	env.asUser()
+	mustFail(t, env.contract.RegisterObserver(env.ctx, "user", "Org1MSP"), "access denied")
+
+	tests := []struct {
+		name  string
+		id    string
+		mspID string
+		want  string
+	}{
+		{"registered observer", "observer1", "ObserverMSP", ""},
+		{"observer ID from another organisation", "observer1", "Org1MSP", "neither an admin nor a registered observer"},
+		{"unregistered client", "observer2", "ObserverMSP", "neither an admin nor a registered observer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.asIdentity(tt.id, tt.mspID, nil)
+			vote, err := env.contract.GetVote(env.ctx, "E1", "V1")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			if vote.CandidateID != "C1" {
+				t.Errorf("vote = %+v, want C1", vote)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Reserved ballot selections accepted by CastVote in place of a candidate ID
+// when the election enables them
+const (
+	SelectionNOTA    = "NOTA"
+	SelectionBlank   = "BLANK"
+	SelectionSpoiled = "SPOILED"
+	writeInPrefix    = "WRITEIN:"
+)
+
+// BallotEntry represents a candidate as it appears on the ballot
+type BallotEntry struct {
+	Position  int        `json:"position"`
+	Candidate *Candidate `json:"candidate"`
+	Withdrawn bool       `json:"withdrawn"`
+}
+
+// Ballot represents the full ballot of an election
+type Ballot struct {
+	ElectionID   string        `json:"electionId"`
+	Entries      []BallotEntry `json:"entries"`
+	AllowNOTA    bool          `json:"allowNota"`
+	AllowBlank   bool          `json:"allowBlank"`
+	AllowWriteIn bool          `json:"allowWriteIn"`
+	AllowAbstain bool          `json:"allowAbstain"`
+}
+
+// isWithdrawn returns true when the candidate has withdrawn from the election
+func isWithdrawn(election *Election, candidateID string) bool {
+	return containsString(election.WithdrawnCandidates, candidateID)
+}
+
+// candidateSerial returns the serial number of a candidate within an
+// election, or 0 when the candidate is not listed. Elections created before
+// serials were recorded fall back to the ballot position
+func candidateSerial(election *Election, candidateID string) int {
+	if serial, ok := election.CandidateSerials[candidateID]; ok {
+		return serial
+	}
+	for i, cID := range election.Candidates {
+		if cID == candidateID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Write-in names may contain letters with their combining marks, such as
+// Devanagari vowel signs, spaces and the punctuation below, plus any
+// characters an election adds through WriteInExtraChars
+const (
+	defaultWriteInMaxLength   = 64
+	defaultWriteInPunctuation = ".-'"
+)
+
+// validateWriteInName checks a write-in name against the election's allowed
+// characters and maximum length, so control characters never reach the ledger
+func validateWriteInName(election *Election, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("write-in name must not be empty")
+	}
+
+	maxLength := election.WriteInMaxLength
+	if maxLength == 0 {
+		maxLength = defaultWriteInMaxLength
+	}
+	if utf8.RuneCountInString(name) > maxLength {
+		return fmt.Errorf("write-in name exceeds %d characters", maxLength)
+	}
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsMark(r) || r == ' ' || strings.ContainsRune(defaultWriteInPunctuation, r) || strings.ContainsRune(election.WriteInExtraChars, r) {
+			continue
+		}
+		return fmt.Errorf("write-in name contains disallowed character %q", r)
+	}
+	return nil
+}
+
+// checkBallotSelection verifies that a selection is valid for the election,
+// either as an enabled reserved selection or a listed, registered and
+// non-withdrawn candidate
+func (s *VotingContract) checkBallotSelection(ctx contractapi.TransactionContextInterface, election *Election, candidateID string) error {
+	if election.Type == ElectionTypeReferendum {
+		if !isReferendumOption(candidateID) {
+			return fmt.Errorf("referendum elections only accept %s, %s or %s", SelectionYes, SelectionNo, SelectionAbstain)
+		}
+		return nil
+	}
+
+	switch {
+	case candidateID == SelectionNOTA:
+		if !election.AllowNOTA {
+			return fmt.Errorf("NOTA is not enabled for this election")
+		}
+		return nil
+	case candidateID == SelectionBlank:
+		if !election.AllowBlank {
+			return fmt.Errorf("blank votes are not enabled for this election")
+		}
+		return nil
+	case candidateID == SelectionSpoiled:
+		if !election.AllowSpoiled {
+			return fmt.Errorf("spoiled ballots are not enabled for this election")
+		}
+		return nil
+	case candidateID == SelectionAbstain:
+		if !election.AllowAbstain {
+			return fmt.Errorf("abstentions are not enabled for this election")
+		}
+		return nil
+	case strings.HasPrefix(candidateID, writeInPrefix):
+		if !election.AllowWriteIn {
+			return fmt.Errorf("write-in votes are not enabled for this election")
+		}
+		return validateWriteInName(election, strings.TrimPrefix(candidateID, writeInPrefix))
+	}
+
+	// Check if candidate is in the election
+	if !containsString(election.Candidates, candidateID) {
+		return fmt.Errorf("candidate is not part of this election")
+	}
+
+	// Check that the listed candidate is actually registered
+	candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateID)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if candidateJSON == nil {
+		return fmt.Errorf("candidate %s listed but not registered", candidateID)
+	}
+
+	if isWithdrawn(election, candidateID) {
+		return fmt.Errorf("candidate %s has withdrawn from this election", candidateID)
+	}
+
+	return nil
+}
+
+// WithdrawCandidate marks a candidate as withdrawn from an election.
+// Callable by the candidate or an admin
+func (s *VotingContract) WithdrawCandidate(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) error {
+	if requireAdmin(ctx) != nil {
+		err := requireCandidate(ctx, candidateID)
+		if err != nil {
+			return err
+		}
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status == "ended" {
+		return fmt.Errorf("cannot withdraw a candidate from an ended election")
+	}
+
+	if !containsString(election.Candidates, candidateID) {
+		return fmt.Errorf("candidate is not part of this election")
+	}
+	if isWithdrawn(election, candidateID) {
+		return fmt.Errorf("candidate %s has already withdrawn from this election", candidateID)
+	}
+
+	election.WithdrawnCandidates = append(election.WithdrawnCandidates, candidateID)
+
+	return s.putElection(ctx, election)
+}
+
+// GetElectionBallot returns the candidates of an election in ballot order,
+// flagging withdrawn ones, along with the reserved selections enabled
+func (s *VotingContract) GetElectionBallot(ctx contractapi.TransactionContextInterface, electionID string) (*Ballot, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ballot := Ballot{
+		ElectionID:   electionID,
+		Entries:      []BallotEntry{},
+		AllowNOTA:    election.AllowNOTA,
+		AllowBlank:   election.AllowBlank,
+		AllowWriteIn: election.AllowWriteIn,
+		AllowAbstain: election.AllowAbstain,
+	}
+
+	for i, candidateID := range election.Candidates {
+		candidate, err := s.GetCandidate(ctx, candidateID)
+		if err != nil {
+			return nil, err
+		}
+		ballot.Entries = append(ballot.Entries, BallotEntry{
+			Position:  i + 1,
+			Candidate: candidate,
+			Withdrawn: isWithdrawn(election, candidateID),
+		})
+	}
+
+	return &ballot, nil
+}
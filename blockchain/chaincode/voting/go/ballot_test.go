@@ -0,0 +1,196 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCastVoteCandidateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		candidateID string
+		want        string
+	}{
+		{"listed but not registered", "GHOST", "candidate GHOST listed but not registered"},
+		{"registered but not listed", "C9", "candidate is not part of this election"},
+		{"neither listed nor registered", "NOBODY", "candidate is not part of this election"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C9")
+			env.registerVoters("North", "V1")
+			env.createElection("E1", "C1", "GHOST")
+			env.setStatus("E1", "active")
+
+			mustFail(t, env.vote("E1", "V1", tt.candidateID), tt.want)
+		})
+	}
+}
+
+func TestGetElectionBallot(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3")
+	env.createElection("E1", "C3", "C1", "C2")
+	env.configure("E1", `{"allowNota":true}`)
+	must(t, env.contract.WithdrawCandidate(env.ctx, "E1", "C1"))
+
+	ballot, err := env.contract.GetElectionBallot(env.ctx, "E1")
+	must(t, err)
+	if !ballot.AllowNOTA || ballot.AllowBlank || ballot.AllowWriteIn {
+		t.Errorf("ballot options = %+v, want only NOTA enabled", ballot)
+	}
+
+	want := []struct {
+		id        string
+		withdrawn bool
+	}{
+		{"C3", false},
+		{"C1", true},
+		{"C2", false},
+	}
+	if len(ballot.Entries) != len(want) {
+		t.Fatalf("ballot has %d entries, want %d", len(ballot.Entries), len(want))
+	}
+	for i, entry := range ballot.Entries {
+		if entry.Position != i+1 || entry.Candidate.ID != want[i].id || entry.Withdrawn != want[i].withdrawn {
+			t.Errorf("entry %d = position %d, %s, withdrawn %v; want %s, withdrawn %v", i, entry.Position, entry.Candidate.ID, entry.Withdrawn, want[i].id, want[i].withdrawn)
+		}
+		if entry.Candidate.Name != "Name "+want[i].id {
+			t.Errorf("entry %d is not resolved to the candidate record: %+v", i, entry.Candidate)
+		}
+	}
+}
+
+func TestWithdrawCandidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		as        func(env *testEnv)
+		candidate string
+		want      string
+	}{
+		{"admin", func(env *testEnv) { env.asAdmin() }, "C1", ""},
+		{"the candidate", func(env *testEnv) { env.asCandidate("C1") }, "C1", ""},
+		{"another candidate", func(env *testEnv) { env.asCandidate("C2") }, "C1", "access denied"},
+		{"ordinary client", func(env *testEnv) { env.asUser() }, "C1", "access denied"},
+		{"unlisted candidate", func(env *testEnv) { env.asAdmin() }, "C9", "not part of this election"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			tt.as(env)
+			err := env.contract.WithdrawCandidate(env.ctx, "E1", tt.candidate)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				if len(env.election("E1").WithdrawnCandidates) != 0 {
+					t.Errorf("candidate was withdrawn despite the refusal")
+				}
+				return
+			}
+			must(t, err)
+
+			env.registerVoters("North", "V9")
+			mustFail(t, env.vote("E1", "V9", tt.candidate), "has withdrawn")
+			mustFail(t, env.contract.WithdrawCandidate(env.ctx, "E1", tt.candidate), "already withdrawn")
+		})
+	}
+}
+
+func TestCandidateSerials(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3")
+	env.registerVoters("North", "V1", "V2")
+	env.createElection("E1", "C3", "C1", "C2")
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C2")
+	env.mustVote("E1", "V2", "C1")
+	env.endElection("E1")
+
+	want := map[string]int{"C3": 1, "C1": 2, "C2": 3}
+	election := env.election("E1")
+	for candidateID, serial := range want {
+		if election.CandidateSerials[candidateID] != serial {
+			t.Errorf("serial of %s = %d, want %d", candidateID, election.CandidateSerials[candidateID], serial)
+		}
+	}
+
+	result := env.results("E1")
+	for _, candidateResult := range result.CandidateResults {
+		if candidateResult.Serial != want[candidateResult.CandidateID] {
+			t.Errorf("result serial of %s = %d, want %d", candidateResult.CandidateID, candidateResult.Serial, want[candidateResult.CandidateID])
+		}
+	}
+
+	// C1 and C2 tie on one vote each; the lower serial comes first
+	sortByVotes(result.CandidateResults)
+	var order []string
+	for _, candidateResult := range result.CandidateResults {
+		order = append(order, candidateResult.CandidateID)
+	}
+	if strings.Join(order, ",") != "C1,C2,C3" {
+		t.Errorf("order = %v, want [C1 C2 C3]", order)
+	}
+}
+
+func TestCandidateSerialFallsBackToBallotPosition(t *testing.T) {
+	election := &Election{Candidates: []string{"C1", "C2"}}
+
+	tests := []struct {
+		candidateID string
+		want        int
+	}{
+		{"C1", 1},
+		{"C2", 2},
+		{"C9", 0},
+	}
+	for _, tt := range tests {
+		if got := candidateSerial(election, tt.candidateID); got != tt.want {
+			t.Errorf("candidateSerial(%s) = %d, want %d", tt.candidateID, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWriteInName(t *testing.T) {
+	tests := []struct {
+		name     string
+		election Election
+		writeIn  string
+		want     string
+	}{
+		{"plain name", Election{}, "Jane O'Neil-Smith Jr.", ""},
+		{"non-Latin letters", Election{}, "सुनीता देवी", ""},
+		{"empty", Election{}, "  ", "must not be empty"},
+		{"digit", Election{}, "Agent 47", "disallowed character '4'"},
+		{"control character", Election{}, "Jane\x00Doe", "disallowed character"},
+		{"newline", Election{}, "Jane\nDoe", "disallowed character"},
+		{"extra character allowed", Election{WriteInExtraChars: "0123456789"}, "Agent 47", ""},
+		{"at the default limit", Election{}, strings.Repeat("a", defaultWriteInMaxLength), ""},
+		{"over the default limit", Election{}, strings.Repeat("a", defaultWriteInMaxLength+1), "exceeds 64 characters"},
+		{"over a custom limit", Election{WriteInMaxLength: 5}, "Johnny", "exceeds 5 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWriteInName(&tt.election, tt.writeIn)
+			if tt.want == "" {
+				must(t, err)
+				return
+			}
+			mustFail(t, err, tt.want)
+		})
+	}
+}
+
+func TestCastVoteWriteIn(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerVoters("North", "V1", "V2")
+	env.createElection("E1")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"writeInExtraChars":"\u0007"}`), "must not contain control characters")
+	env.configure("E1", `{"allowWriteIn":true,"writeInMaxLength":10}`)
+	env.setStatus("E1", "active")
+
+	mustFail(t, env.vote("E1", "V1", writeInPrefix+"Bob;--"), "disallowed character")
+	env.mustVote("E1", "V2", writeInPrefix+"Jane Doe")
+}
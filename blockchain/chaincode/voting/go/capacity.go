@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// VoteCounter holds the running number of votes cast in an election. It is
+// only maintained for elections with a MaxTotalVotes cap, so that uncapped
+// elections do not serialize every vote on a shared key
+type VoteCounter struct {
+	ElectionID string `json:"electionId"`
+	Count      int    `json:"count"`
+}
+
+// getVoteCounter returns the running vote count of an election
+func (s *VotingContract) getVoteCounter(ctx contractapi.TransactionContextInterface, electionID string) (*VoteCounter, error) {
+	counter := VoteCounter{
+		ElectionID: electionID,
+	}
+
+	counterJSON, err := ctx.GetStub().GetState("VOTECOUNT_" + electionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if counterJSON == nil {
+		return &counter, nil
+	}
+
+	err = json.Unmarshal(counterJSON, &counter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counter, nil
+}
+
+// capacityReached returns true when a capped election has received its maximum number of votes
+func (s *VotingContract) capacityReached(ctx contractapi.TransactionContextInterface, election *Election) (bool, error) {
+	if election.MaxTotalVotes == 0 {
+		return false, nil
+	}
+
+	counter, err := s.getVoteCounter(ctx, election.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return counter.Count >= election.MaxTotalVotes, nil
+}
+
+// countVote increments the running vote count of a capped election
+func (s *VotingContract) countVote(ctx contractapi.TransactionContextInterface, election *Election) error {
+	if election.MaxTotalVotes == 0 {
+		return nil
+	}
+
+	counter, err := s.getVoteCounter(ctx, election.ID)
+	if err != nil {
+		return err
+	}
+	counter.Count++
+
+	counterJSON, err := json.Marshal(counter)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("VOTECOUNT_"+election.ID, counterJSON)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMaxTotalVotes(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   string
+		voters    int
+		wantVoted int
+	}{
+		{"no cap", `{}`, 4, 4},
+		{"below the cap", `{"maxTotalVotes":5}`, 4, 4},
+		{"up to the cap", `{"maxTotalVotes":3}`, 3, 3},
+		{"beyond the cap", `{"maxTotalVotes":2}`, 4, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("E1", "C1")
+			env.configure("E1", tt.options)
+			env.setStatus("E1", "active")
+
+			for i := 1; i <= tt.voters; i++ {
+				err := env.vote("E1", fmt.Sprintf("V%d", i), "C1")
+				if i <= tt.wantVoted {
+					must(t, err)
+				} else {
+					mustFail(t, err, "voting capacity reached")
+				}
+			}
+
+			env.endElection("E1")
+			if total := env.results("E1").TotalVotes; total != tt.wantVoted {
+				t.Errorf("total votes = %d, want %d", total, tt.wantVoted)
+			}
+		})
+	}
+}
+
+func TestMaxTotalVotesValidated(t *testing.T) {
+	env := newTestEnv(t)
+	env.createElection("E1")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"maxTotalVotes":-1}`), "maxTotalVotes")
+	env.configure("E1", `{"maxTotalVotes":0}`)
+	if env.election("E1").MaxTotalVotes != 0 {
+		t.Errorf("a zero cap was not stored")
+	}
+}
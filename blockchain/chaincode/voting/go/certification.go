@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CandidateDiscrepancy describes a candidate whose stored and recomputed counts differ
+type CandidateDiscrepancy struct {
+	CandidateID     string `json:"candidateId"`
+	StoredVotes     int    `json:"storedVotes"`
+	RecomputedVotes int    `json:"recomputedVotes"`
+	Difference      int    `json:"difference"` // Recomputed minus stored
+}
+
+// ResultDiff compares the finalized result of an election with a fresh tally
+type ResultDiff struct {
+	ElectionID      string                 `json:"electionId"`
+	Matches         bool                   `json:"matches"`
+	StoredTotal     int                    `json:"storedTotal"`
+	RecomputedTotal int                    `json:"recomputedTotal"`
+	StoredHash      string                 `json:"storedHash"`
+	RecomputedHash  string                 `json:"recomputedHash"`
+	Discrepancies   []CandidateDiscrepancy `json:"discrepancies"`
+}
+
+// getFinalizedResult returns the stored result of an election, or nil when it has not been finalized
+func (s *VotingContract) getFinalizedResult(ctx contractapi.TransactionContextInterface, electionID string) (*ElectionResult, error) {
+	resultJSON, err := ctx.GetStub().GetState("RESULT_" + electionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if resultJSON == nil {
+		return nil, nil
+	}
+
+	var result ElectionResult
+	err = json.Unmarshal(resultJSON, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// checkNotCertified returns an error once the results of an election have
+// been certified, as the election is then immutable
+func checkNotCertified(election *Election) error {
+	if election.Certified {
+		return fmt.Errorf("election certified and locked")
+	}
+	return nil
+}
+
+// FinalizeResults tallies an ended election, stores the result under
+// RESULT_<electionID> as the official record and certifies the election,
+// locking it against further changes. Admin only
+func (s *VotingContract) FinalizeResults(ctx contractapi.TransactionContextInterface, electionID string) (*ElectionResult, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getFinalizedResult(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("results for election %s have already been finalized", electionID)
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.GetStub().PutState("RESULT_"+electionID, resultJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	election.Certified = true
+	err = s.storeElection(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CertifyConstituency records that counting in one constituency of an ended
+// election is complete, ahead of certifying the whole election. Admin only
+func (s *VotingContract) CertifyConstituency(ctx contractapi.TransactionContextInterface, electionID string, constituency string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status != "ended" {
+		return &ElectionNotEndedError{ElectionID: electionID, Status: election.Status}
+	}
+
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return err
+	}
+	if !containsString(constituencies, constituency) {
+		return fmt.Errorf("election %s does not cover constituency %s", electionID, constituency)
+	}
+	if containsString(election.CertifiedConstituencies, constituency) {
+		return fmt.Errorf("constituency %s of election %s has already been certified", constituency, electionID)
+	}
+
+	election.CertifiedConstituencies = append(election.CertifiedConstituencies, constituency)
+
+	return s.putElection(ctx, election)
+}
+
+// GetCountingProgress returns, per constituency covered by an election,
+// whether its count has been certified. Certifying the whole election through
+// FinalizeResults completes every constituency
+func (s *VotingContract) GetCountingProgress(ctx contractapi.TransactionContextInterface, electionID string) (map[string]bool, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]bool)
+	for _, constituency := range constituencies {
+		progress[constituency] = election.Certified || containsString(election.CertifiedConstituencies, constituency)
+	}
+
+	return progress, nil
+}
+
+// RecountElection recomputes the result of an ended election from its raw
+// vote records, ignoring any finalized result stored under RESULT_. Use
+// CompareResults to check the recount against the finalized record
+func (s *VotingContract) RecountElection(ctx contractapi.TransactionContextInterface, electionID string) (*ElectionResult, error) {
+	_, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CompareResults recomputes the tally of an election from the raw votes and
+// reports any difference from its finalized result
+func (s *VotingContract) CompareResults(ctx contractapi.TransactionContextInterface, electionID string) (*ResultDiff, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.getFinalizedResult(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("results for election %s have not been finalized", electionID)
+	}
+
+	recomputed, err := s.tallyElection(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffResults(stored, recomputed), nil
+}
+
+// diffResults compares two tallies of the same election candidate by candidate
+func diffResults(stored *ElectionResult, recomputed *ElectionResult) *ResultDiff {
+	diff := ResultDiff{
+		ElectionID:      stored.ElectionID,
+		StoredTotal:     stored.TotalVotes,
+		RecomputedTotal: recomputed.TotalVotes,
+		StoredHash:      stored.ResultHash,
+		RecomputedHash:  recomputed.ResultHash,
+		Discrepancies:   []CandidateDiscrepancy{},
+	}
+
+	storedVotes := make(map[string]int)
+	recomputedVotes := make(map[string]int)
+	var candidateIDs []string
+	for _, candidateResult := range stored.CandidateResults {
+		storedVotes[candidateResult.CandidateID] = candidateResult.VoteCount
+		candidateIDs = append(candidateIDs, candidateResult.CandidateID)
+	}
+	for _, candidateResult := range recomputed.CandidateResults {
+		recomputedVotes[candidateResult.CandidateID] = candidateResult.VoteCount
+		if _, ok := storedVotes[candidateResult.CandidateID]; !ok {
+			candidateIDs = append(candidateIDs, candidateResult.CandidateID)
+		}
+	}
+	sort.Strings(candidateIDs)
+
+	for _, candidateID := range candidateIDs {
+		if storedVotes[candidateID] == recomputedVotes[candidateID] {
+			continue
+		}
+		diff.Discrepancies = append(diff.Discrepancies, CandidateDiscrepancy{
+			CandidateID:     candidateID,
+			StoredVotes:     storedVotes[candidateID],
+			RecomputedVotes: recomputedVotes[candidateID],
+			Difference:      recomputedVotes[candidateID] - storedVotes[candidateID],
+		})
+	}
+
+	diff.Matches = len(diff.Discrepancies) == 0 && stored.ResultHash == recomputed.ResultHash
+
+	return &diff
+}
+
+// tallyConsistent returns true when a tally's total equals the sum of its
+// candidate, NOTA, blank, write-in, spoiled and abstain counts
+func tallyConsistent(result *ElectionResult) bool {
+	sum := result.NOTAVotes + result.BlankVotes + result.WriteInVotes + result.SpoiledVotes + result.AbstainVotes
+	for _, candidateResult := range result.CandidateResults {
+		sum += candidateResult.VoteCount
+	}
+	return sum == result.TotalVotes
+}
+
+// VerifyTallyConsistency reports whether the total votes of an election equal
+// the sum of its individual counts. The finalized result is checked when
+// there is one, otherwise a fresh tally of the ended election
+func (s *VotingContract) VerifyTallyConsistency(ctx contractapi.TransactionContextInterface, electionID string) (bool, error) {
+	stored, err := s.getFinalizedResult(ctx, electionID)
+	if err != nil {
+		return false, err
+	}
+	if stored != nil {
+		return tallyConsistent(stored), nil
+	}
+
+	_, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return false, err
+	}
+
+	return tallyConsistent(result), nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		stored      map[string]int
+		recomputed  map[string]int
+		wantMatches bool
+		want        []CandidateDiscrepancy
+	}{
+		{"identical", map[string]int{"C1": 2, "C2": 1}, map[string]int{"C1": 2, "C2": 1}, true, nil},
+		{
+			name:       "vote moved between candidates",
+			stored:     map[string]int{"C1": 2, "C2": 1},
+			recomputed: map[string]int{"C1": 1, "C2": 2},
+			want: []CandidateDiscrepancy{
+				{CandidateID: "C1", StoredVotes: 2, RecomputedVotes: 1, Difference: -1},
+				{CandidateID: "C2", StoredVotes: 1, RecomputedVotes: 2, Difference: 1},
+			},
+		},
+		{
+			name:       "candidate missing from the stored result",
+			stored:     map[string]int{"C1": 2},
+			recomputed: map[string]int{"C1": 2, "C3": 1},
+			want: []CandidateDiscrepancy{
+				{CandidateID: "C3", StoredVotes: 0, RecomputedVotes: 1, Difference: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffResults(resultOf(tt.stored), resultOf(tt.recomputed))
+			if diff.Matches != tt.wantMatches {
+				t.Errorf("matches = %v, want %v", diff.Matches, tt.wantMatches)
+			}
+			if len(diff.Discrepancies) != len(tt.want) {
+				t.Fatalf("discrepancies = %+v, want %+v", diff.Discrepancies, tt.want)
+			}
+			for i, discrepancy := range diff.Discrepancies {
+				if discrepancy != tt.want[i] {
+					t.Errorf("discrepancy %d = %+v, want %+v", i, discrepancy, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// resultOf builds a hashed election result from candidate vote counts
+func resultOf(votes map[string]int) *ElectionResult {
+	result := &ElectionResult{ElectionID: "E1"}
+	for candidateID, count := range votes {
+		result.CandidateResults = append(result.CandidateResults, CandidateResult{CandidateID: candidateID, VoteCount: count})
+		result.TotalVotes += count
+	}
+	result.ResultHash = computeResultHash(result)
+	return result
+}
+
+func TestCompareResults(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C1")
+	env.mustVote("E1", "V3", "C2")
+
+	_, err := env.contract.CompareResults(env.ctx, "E1")
+	mustFail(t, err, "have not been finalized")
+	_, err = env.contract.FinalizeResults(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+
+	env.endElection("E1")
+	_, err = env.contract.FinalizeResults(env.ctx, "E1")
+	must(t, err)
+	_, err = env.contract.FinalizeResults(env.ctx, "E1")
+	mustFail(t, err, "already been finalized")
+
+	diff, err := env.contract.CompareResults(env.ctx, "E1")
+	must(t, err)
+	if !diff.Matches || len(diff.Discrepancies) != 0 {
+		t.Errorf("diff = %+v, want a match", diff)
+	}
+
+	// Tamper with a raw vote record behind the contract's back
+	vote, err := env.contract.GetVote(env.ctx, "E1", "V1")
+	must(t, err)
+	vote.CandidateID = "C2"
+	voteJSON, err := json.Marshal(vote)
+	must(t, err)
+	must(t, env.stub.PutState("VOTE_E1_V1", voteJSON))
+
+	diff, err = env.contract.CompareResults(env.ctx, "E1")
+	must(t, err)
+	if diff.Matches || len(diff.Discrepancies) != 2 || diff.StoredHash == diff.RecomputedHash {
+		t.Errorf("diff = %+v, want the moved vote reported", diff)
+	}
+}
+
+func TestRecountElection(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C2")
+
+	_, err := env.contract.RecountElection(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+	env.endElection("E1")
+	finalized, err := env.contract.FinalizeResults(env.ctx, "E1")
+	must(t, err)
+
+	// A vote record added behind the contract's back shows up in the recount only
+	voteJSON, err := json.Marshal(Vote{ElectionID: "E1", VoterID: "V3", CandidateID: "C1"})
+	must(t, err)
+	must(t, env.stub.PutState("VOTE_E1_V3", voteJSON))
+
+	recount, err := env.contract.RecountElection(env.ctx, "E1")
+	must(t, err)
+	if recount.TotalVotes != 3 || finalized.TotalVotes != 2 {
+		t.Errorf("recount has %d votes and the finalized result %d, want 3 and 2", recount.TotalVotes, finalized.TotalVotes)
+	}
+	if votes := candidateVotes(recount); votes["C1"] != 2 || votes["C2"] != 1 {
+		t.Errorf("recount votes = %v", votes)
+	}
+	if recount.ResultHash == finalized.ResultHash {
+		t.Errorf("recount hash matches the stale finalized result")
+	}
+}
+
+func TestCertifiedElectionLocked(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(env *testEnv) error
+	}{
+		{"status change", func(env *testEnv) error {
+			return env.contract.UpdateElectionStatus(env.ctx, "E1", "active")
+		}},
+		{"metadata", func(env *testEnv) error {
+			return env.contract.SetElectionMetadata(env.ctx, "E1", "note", "late edit")
+		}},
+		{"tie break", func(env *testEnv) error {
+			_, err := env.contract.ResolveTieByLot(env.ctx, "E1", "seed")
+			return err
+		}},
+		{"nomination fee", func(env *testEnv) error {
+			return env.contract.RecordNominationFee(env.ctx, "E1", "C1", 100)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			env.mustVote("E1", "V1", "C1")
+			env.mustVote("E1", "V2", "C2")
+			env.endElection("E1")
+			_, err := env.contract.FinalizeResults(env.ctx, "E1")
+			must(t, err)
+			election := env.election("E1")
+			if !election.Certified {
+				t.Fatalf("election is not certified after FinalizeResults")
+			}
+
+			mustFail(t, tt.change(env), "election certified and locked")
+			if after := env.election("E1"); after.Version != election.Version || after.Status != "ended" {
+				t.Errorf("certified election changed to version %d, status %s", after.Version, after.Status)
+			}
+		})
+	}
+}
+
+func TestMergeConstituenciesSkipsCertifiedElections(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("Old", "C1")
+	env.registerCandidates("New", "C2")
+	env.registerVoters("Old", "V1")
+	env.createElection("E1", "C1", "C2")
+	env.configure("E1", `{"constituencies":["Old"]}`)
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C1")
+	env.endElection("E1")
+	_, err := env.contract.FinalizeResults(env.ctx, "E1")
+	must(t, err)
+
+	report, err := env.contract.MergeConstituencies(env.ctx, "Old", "New")
+	must(t, err)
+	if report.ElectionsUpdated != 0 || report.VotersMoved != 1 {
+		t.Errorf("report = %+v, want the voter moved and no election updated", report)
+	}
+	if got := env.election("E1").Constituencies; len(got) != 1 || got[0] != "Old" {
+		t.Errorf("certified election constituencies = %v, want [Old]", got)
+	}
+}
+
+func TestGetCountingProgress(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerCandidates("South", "C2")
+	env.createElection("E1", "C1", "C2")
+	env.setStatus("E1", "active")
+
+	err := env.contract.CertifyConstituency(env.ctx, "E1", "North")
+	var notEnded *ElectionNotEndedError
+	if !errors.As(err, &notEnded) {
+		t.Fatalf("certifying an active election: got %v, want ElectionNotEndedError", err)
+	}
+	env.endElection("E1")
+
+	env.asUser()
+	mustFail(t, env.contract.CertifyConstituency(env.ctx, "E1", "North"), "access denied")
+	env.asAdmin()
+	mustFail(t, env.contract.CertifyConstituency(env.ctx, "E1", "East"), "does not cover constituency East")
+	must(t, env.contract.CertifyConstituency(env.ctx, "E1", "North"))
+	mustFail(t, env.contract.CertifyConstituency(env.ctx, "E1", "North"), "already been certified")
+
+	tests := []struct {
+		name     string
+		finalize bool
+		want     map[string]bool
+	}{
+		{"one constituency certified", false, map[string]bool{"North": true, "South": false}},
+		{"whole election certified", true, map[string]bool{"North": true, "South": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.finalize {
+				_, err := env.contract.FinalizeResults(env.ctx, "E1")
+				must(t, err)
+			}
+
+			progress, err := env.contract.GetCountingProgress(env.ctx, "E1")
+			must(t, err)
+			if len(progress) != len(tt.want) {
+				t.Fatalf("progress = %v, want %v", progress, tt.want)
+			}
+			for constituency, done := range tt.want {
+				if progress[constituency] != done {
+					t.Errorf("%s complete = %v, want %v", constituency, progress[constituency], done)
+				}
+			}
+		})
+	}
+}
+
+func TestTallyConsistent(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ElectionResult
+		want   bool
+	}{
+		{"empty", ElectionResult{}, true},
+		{"candidates only", ElectionResult{TotalVotes: 3, CandidateResults: []CandidateResult{{VoteCount: 2}, {VoteCount: 1}}}, true},
+		{"every kind of ballot", ElectionResult{TotalVotes: 7, NOTAVotes: 1, BlankVotes: 1, WriteInVotes: 1, SpoiledVotes: 1, AbstainVotes: 1,
+			CandidateResults: []CandidateResult{{VoteCount: 2}}}, true},
+		{"total too high", ElectionResult{TotalVotes: 4, CandidateResults: []CandidateResult{{VoteCount: 2}, {VoteCount: 1}}}, false},
+		{"total too low", ElectionResult{TotalVotes: 2, NOTAVotes: 1, CandidateResults: []CandidateResult{{VoteCount: 2}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tallyConsistent(&tt.result); got != tt.want {
+				t.Errorf("tallyConsistent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTallyConsistency(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6")
+	env.createElection("E1", "C1", "C2")
+	env.configure("E1", `{"allowNota":true,"allowBlank":true,"allowWriteIn":true,"allowSpoiled":true}`)
+	env.setStatus("E1", "active")
+	for voterID, selection := range map[string]string{
+		"V1": "C1", "V2": "C2", "V3": SelectionNOTA, "V4": SelectionBlank, "V5": SelectionSpoiled, "V6": writeInPrefix + "Jane Doe",
+	} {
+		env.mustVote("E1", voterID, selection)
+	}
+
+	_, err := env.contract.VerifyTallyConsistency(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+	env.endElection("E1")
+
+	consistent, err := env.contract.VerifyTallyConsistency(env.ctx, "E1")
+	must(t, err)
+	if !consistent {
+		t.Errorf("fresh tally is inconsistent")
+	}
+
+	finalized, err := env.contract.FinalizeResults(env.ctx, "E1")
+	must(t, err)
+	consistent, err = env.contract.VerifyTallyConsistency(env.ctx, "E1")
+	must(t, err)
+	if !consistent {
+		t.Errorf("finalized result is inconsistent")
+	}
+
+	// Inflate the stored total, as tampering with the finalized record would
+	finalized.TotalVotes++
+	resultJSON, err := json.Marshal(finalized)
+	must(t, err)
+	must(t, env.stub.PutState("RESULT_E1", resultJSON))
+	consistent, err = env.contract.VerifyTallyConsistency(env.ctx, "E1")
+	must(t, err)
+	if consistent {
+		t.Errorf("inflated finalized total reported as consistent")
+	}
+}
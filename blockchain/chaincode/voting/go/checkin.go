@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CheckIn records that a voter arrived to vote in an election
+type CheckIn struct {
+	ElectionID string    `json:"electionId"`
+	VoterID    string    `json:"voterId"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// isCheckedIn returns true when the voter has checked in for the election
+func (s *VotingContract) isCheckedIn(ctx contractapi.TransactionContextInterface, electionID string, voterID string) (bool, error) {
+	checkInJSON, err := ctx.GetStub().GetState("CHECKIN_" + electionID + "_" + voterID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	return checkInJSON != nil, nil
+}
+
+// CheckInVoter records that a voter has arrived to vote in an active election
+func (s *VotingContract) CheckInVoter(ctx contractapi.TransactionContextInterface, electionID string, voterID string) error {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status != "active" {
+		return fmt.Errorf("election is not active")
+	}
+
+	_, err = s.GetVoter(ctx, voterID)
+	if err != nil {
+		return err
+	}
+
+	checkedIn, err := s.isCheckedIn(ctx, electionID, voterID)
+	if err != nil {
+		return err
+	}
+	if checkedIn {
+		return fmt.Errorf("voter %s has already checked in for this election", voterID)
+	}
+
+	timestamp, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	checkIn := CheckIn{
+		ElectionID: electionID,
+		VoterID:    voterID,
+		Timestamp:  timestamp,
+	}
+	checkInJSON, err := json.Marshal(checkIn)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("CHECKIN_"+electionID+"_"+voterID, checkInJSON)
+}
+
+// getElectionCheckIns returns every check-in recorded for the given election
+func (s *VotingContract) getElectionCheckIns(ctx contractapi.TransactionContextInterface, electionID string) ([]*CheckIn, error) {
+	prefix := "CHECKIN_" + electionID + "_"
+	resultsIterator, err := ctx.GetStub().GetStateByRange(prefix, prefixRangeEnd(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var checkIns []*CheckIn
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var checkIn CheckIn
+		err = json.Unmarshal(queryResponse.Value, &checkIn)
+		if err != nil {
+			return nil, err
+		}
+		// Election IDs may share a prefix, e.g. "E1" and "E1_B"
+		if checkIn.ElectionID != electionID {
+			continue
+		}
+		checkIns = append(checkIns, &checkIn)
+	}
+
+	return checkIns, nil
+}
+
+// GetCheckIns returns the number of voters checked in for an election
+func (s *VotingContract) GetCheckIns(ctx contractapi.TransactionContextInterface, electionID string) (int, error) {
+	_, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+
+	checkIns, err := s.getElectionCheckIns(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(checkIns), nil
+}
+
+// CheckedInResults is an election tally restricted to voters who checked in
+type CheckedInResults struct {
+	Result        *ElectionResult `json:"result"`
+	ExcludedVotes int             `json:"excludedVotes"` // Votes without a matching check-in
+}
+
+// GetResultsCheckedInOnly tallies an ended election counting only votes
+// from voters with a check-in record, reporting how many were excluded
+func (s *VotingContract) GetResultsCheckedInOnly(ctx contractapi.TransactionContextInterface, electionID string) (*CheckedInResults, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	if election.Status != "ended" {
+		return nil, &ElectionNotEndedError{ElectionID: electionID, Status: election.Status}
+	}
+	err = checkResultsReleasable(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	checkIns, err := s.getElectionCheckIns(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	checkedIn := make(map[string]bool)
+	for _, checkIn := range checkIns {
+		checkedIn[checkIn.VoterID] = true
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := CheckedInResults{}
+	var counted []*Vote
+	for _, vote := range votes {
+		if !checkedIn[vote.VoterID] {
+			results.ExcludedVotes++
+			continue
+		}
+		counted = append(counted, vote)
+	}
+	results.Result = tallyVotes(election, counted)
+
+	return &results, nil
+}
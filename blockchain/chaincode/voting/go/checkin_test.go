@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCheckInVoter(t *testing.T) {
+	env := setupElection(t)
+	env.createElection("E2", "C1")
+
+	must(t, env.contract.CheckInVoter(env.ctx, "E1", "V1"))
+	must(t, env.contract.CheckInVoter(env.ctx, "E1", "V2"))
+
+	tests := []struct {
+		name       string
+		electionID string
+		voterID    string
+		want       string
+	}{
+		{"checked in twice", "E1", "V1", "already checked in"},
+		{"election not active", "E2", "V3", "not active"},
+		{"unregistered voter", "E1", "V9", "does not exist"},
+		{"unknown election", "E9", "V3", "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mustFail(t, env.contract.CheckInVoter(env.ctx, tt.electionID, tt.voterID), tt.want)
+		})
+	}
+
+	// Checking in does not count as voting
+	env.mustVote("E1", "V3", "C1")
+	count, err := env.contract.GetCheckIns(env.ctx, "E1")
+	must(t, err)
+	if count != 2 {
+		t.Errorf("GetCheckIns = %d, want 2", count)
+	}
+	count, err = env.contract.GetCheckIns(env.ctx, "E2")
+	must(t, err)
+	if count != 0 {
+		t.Errorf("GetCheckIns for E2 = %d, want 0", count)
+	}
+}
+
+func TestRequireCheckIn(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerVoters("North", "V1", "V2")
+	env.createElection("E1", "C1")
+	env.configure("E1", `{"requireCheckIn":true}`)
+	env.setStatus("E1", "active")
+	must(t, env.contract.CheckInVoter(env.ctx, "E1", "V1"))
+
+	env.mustVote("E1", "V1", "C1")
+	mustFail(t, env.vote("E1", "V2", "C1"), "has not checked in")
+}
+
+func TestGetResultsCheckedInOnly(t *testing.T) {
+	env := setupElection(t)
+	must(t, env.contract.CheckInVoter(env.ctx, "E1", "V1"))
+	must(t, env.contract.CheckInVoter(env.ctx, "E1", "V2"))
+	must(t, env.contract.CheckInVoter(env.ctx, "E1", "V4"))
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C2")
+	env.mustVote("E1", "V3", "C2")
+
+	_, err := env.contract.GetResultsCheckedInOnly(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+	env.endElection("E1")
+
+	results, err := env.contract.GetResultsCheckedInOnly(env.ctx, "E1")
+	must(t, err)
+	if results.ExcludedVotes != 1 || results.Result.TotalVotes != 2 {
+		t.Errorf("excluded %d, counted %d; want 1 and 2", results.ExcludedVotes, results.Result.TotalVotes)
+	}
+	if votes := candidateVotes(results.Result); votes["C1"] != 1 || votes["C2"] != 1 {
+		t.Errorf("votes = %v, want one each", votes)
+	}
+	if full := env.results("E1"); full.TotalVotes != 3 {
+		t.Errorf("full results count %d votes, want 3", full.TotalVotes)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const configKey = "CONFIG_CONTRACT"
+
+// defaultVoterIDPattern accepts a 12-digit Aadhaar number or an EPIC voter ID
+const defaultVoterIDPattern = `^([0-9]{12}|[A-Z]{3}[0-9]{7})$`
+
+// defaultVoteCooldownSeconds is the default minimum gap between vote attempts by the same voter in an election
+const defaultVoteCooldownSeconds = 30
+
+// defaultDepositForfeitureFraction is the share of valid votes below which a
+// candidate forfeits their deposit
+const defaultDepositForfeitureFraction = 1.0 / 6
+
+// ContractConfig holds contract-wide settings
+type ContractConfig struct {
+	VoterIDPattern               string  `json:"voterIdPattern"`               // Regular expression enforced by RegisterVoterStrict
+	VoteCooldownSeconds          int     `json:"voteCooldownSeconds"`          // Minimum gap between vote attempts by the same voter in an election
+	ExclusiveCandidacy           bool    `json:"exclusiveCandidacy"`           // Forbid candidates from standing in two open elections at once
+	DepositForfeitureFraction    float64 `json:"depositForfeitureFraction"`    // Share of valid votes a candidate needs to keep their deposit
+	MaxCandidatesPerConstituency int     `json:"maxCandidatesPerConstituency"` // Cap on an election's candidates in one constituency, 0 for no cap
+	StrictElectionEnd            bool    `json:"strictElectionEnd"`            // Refuse to end elections before their EndTime through UpdateElectionStatus
+	AllowSampleData              bool    `json:"allowSampleData"`              // Let SeedSampleData run, for development networks only
+}
+
+// ConfigUpdate holds the settings changed by SetConfig. Fields left out of
+// the JSON keep their current value
+type ConfigUpdate struct {
+	VoterIDPattern               *string  `json:"voterIdPattern,omitempty"`
+	VoteCooldownSeconds          *int     `json:"voteCooldownSeconds,omitempty"`
+	ExclusiveCandidacy           *bool    `json:"exclusiveCandidacy,omitempty"`
+	DepositForfeitureFraction    *float64 `json:"depositForfeitureFraction,omitempty"`
+	MaxCandidatesPerConstituency *int     `json:"maxCandidatesPerConstituency,omitempty"`
+	StrictElectionEnd            *bool    `json:"strictElectionEnd,omitempty"`
+	AllowSampleData              *bool    `json:"allowSampleData,omitempty"`
+}
+
+// getConfig returns the stored contract configuration, or the defaults when none is stored
+func getConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	config := ContractConfig{
+		VoterIDPattern:            defaultVoterIDPattern,
+		VoteCooldownSeconds:       defaultVoteCooldownSeconds,
+		DepositForfeitureFraction: defaultDepositForfeitureFraction,
+	}
+
+	configJSON, err := ctx.GetStub().GetState(configKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if configJSON == nil {
+		return &config, nil
+	}
+
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// GetConfig returns the contract configuration
+func (s *VotingContract) GetConfig(ctx contractapi.TransactionContextInterface) (*ContractConfig, error) {
+	return getConfig(ctx)
+}
+
+// SetConfig updates the contract configuration. Admin only
+func (s *VotingContract) SetConfig(ctx contractapi.TransactionContextInterface, configJSON string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	var update ConfigUpdate
+	decoder := json.NewDecoder(bytes.NewReader([]byte(configJSON)))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&update)
+	if err != nil {
+		return fmt.Errorf("invalid config JSON: %v", err)
+	}
+
+	config, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if update.VoterIDPattern != nil {
+		_, err := regexp.Compile(*update.VoterIDPattern)
+		if err != nil {
+			return fmt.Errorf("invalid voter ID pattern: %v", err)
+		}
+		config.VoterIDPattern = *update.VoterIDPattern
+	}
+	if update.VoteCooldownSeconds != nil {
+		err := validateNonNegativeInt("voteCooldownSeconds", *update.VoteCooldownSeconds)
+		if err != nil {
+			return err
+		}
+		config.VoteCooldownSeconds = *update.VoteCooldownSeconds
+	}
+	if update.ExclusiveCandidacy != nil {
+		config.ExclusiveCandidacy = *update.ExclusiveCandidacy
+	}
+	if update.DepositForfeitureFraction != nil {
+		err := validateFraction("depositForfeitureFraction", *update.DepositForfeitureFraction)
+		if err != nil {
+			return err
+		}
+		config.DepositForfeitureFraction = *update.DepositForfeitureFraction
+	}
+	if update.MaxCandidatesPerConstituency != nil {
+		err := validateNonNegativeInt("maxCandidatesPerConstituency", *update.MaxCandidatesPerConstituency)
+		if err != nil {
+			return err
+		}
+		config.MaxCandidatesPerConstituency = *update.MaxCandidatesPerConstituency
+	}
+	if update.StrictElectionEnd != nil {
+		config.StrictElectionEnd = *update.StrictElectionEnd
+	}
+	if update.AllowSampleData != nil {
+		config.AllowSampleData = *update.AllowSampleData
+	}
+
+	newConfigJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(configKey, newConfigJSON)
+}
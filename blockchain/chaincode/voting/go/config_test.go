@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRegisterVoterStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		voterID string
+		want    string
+	}{
+		{"Aadhaar number", "", "123456789012", ""},
+		{"EPIC number", "", "ABC1234567", ""},
+		{"too short", "", "12345", "malformed voter ID"},
+		{"lowercase EPIC", "", "abc1234567", "malformed voter ID"},
+		{"custom pattern accepts", `{"voterIdPattern":"^V[0-9]+$"}`, "V42", ""},
+		{"custom pattern rejects", `{"voterIdPattern":"^V[0-9]+$"}`, "123456789012", "malformed voter ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			if tt.config != "" {
+				env.setConfig(tt.config)
+			}
+			err := env.contract.RegisterVoterStrict(env.ctx, tt.voterID, "Voter", "North")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			_, err = env.contract.GetVoter(env.ctx, tt.voterID)
+			must(t, err)
+		})
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	env := newTestEnv(t)
+
+	config, err := env.contract.GetConfig(env.ctx)
+	must(t, err)
+	if config.VoterIDPattern != defaultVoterIDPattern {
+		t.Errorf("default pattern = %q, want %q", config.VoterIDPattern, defaultVoterIDPattern)
+	}
+
+	mustFail(t, env.contract.SetConfig(env.ctx, `{"voterIdPattern":"("}`), "invalid voter ID pattern")
+	mustFail(t, env.contract.SetConfig(env.ctx, `{"unknown":1}`), "invalid config JSON")
+	env.asUser()
+	mustFail(t, env.contract.SetConfig(env.ctx, `{"voterIdPattern":"^V$"}`), "access denied")
+
+	config, err = env.contract.GetConfig(env.ctx)
+	must(t, err)
+	if config.VoterIDPattern != defaultVoterIDPattern {
+		t.Errorf("pattern changed to %q by a refused update", config.VoterIDPattern)
+	}
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// MergeReport summarises the records moved by MergeConstituencies
+type MergeReport struct {
+	FromConstituency string `json:"fromConstituency"`
+	ToConstituency   string `json:"toConstituency"`
+	VotersMoved      int    `json:"votersMoved"`
+	CandidatesMoved  int    `json:"candidatesMoved"`
+	ElectionsUpdated int    `json:"electionsUpdated"` // Elections whose declared constituencies, closing times or phases were remapped
+}
+
+// electionConstituencies returns the sorted distinct constituencies covered
+// by an election: those declared on it plus those of its registered candidates
+func (s *VotingContract) electionConstituencies(ctx contractapi.TransactionContextInterface, election *Election) ([]string, error) {
+	seen := make(map[string]bool)
+	var constituencies []string
+	for _, constituency := range election.Constituencies {
+		if !seen[constituency] {
+			seen[constituency] = true
+			constituencies = append(constituencies, constituency)
+		}
+	}
+	for _, candidateID := range election.Candidates {
+		candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if candidateJSON == nil {
+			continue // Unregistered candidates do not cover any constituency
+		}
+
+		var candidate Candidate
+		err = json.Unmarshal(candidateJSON, &candidate)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[candidate.Constituency] {
+			seen[candidate.Constituency] = true
+			constituencies = append(constituencies, candidate.Constituency)
+		}
+	}
+	sort.Strings(constituencies)
+
+	return constituencies, nil
+}
+
+// checkCandidatesPerConstituency returns an error when more than max of the
+// given candidates are registered in the same constituency. Unregistered
+// candidates have no constituency and are not counted
+func (s *VotingContract) checkCandidatesPerConstituency(ctx contractapi.TransactionContextInterface, candidateIDs []string, max int) error {
+	counts := make(map[string]int)
+	for _, candidateID := range candidateIDs {
+		candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateID)
+		if err != nil {
+			return fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if candidateJSON == nil {
+			continue
+		}
+
+		var candidate Candidate
+		err = json.Unmarshal(candidateJSON, &candidate)
+		if err != nil {
+			return err
+		}
+		counts[candidate.Constituency]++
+		if counts[candidate.Constituency] > max {
+			return fmt.Errorf("constituency %s exceeds the limit of %d candidates per constituency", candidate.Constituency, max)
+		}
+	}
+
+	return nil
+}
+
+// constituencySettingsConflict returns an error when voters of
+// fromConstituency would poll under a different closing time or phase once
+// merged into toConstituency, as both are used by the election
+func (s *VotingContract) constituencySettingsConflict(ctx contractapi.TransactionContextInterface, election *Election, fromConstituency string, toConstituency string) error {
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return err
+	}
+
+	fromEnd, fromHasEnd := election.ConstituencyEndTimes[fromConstituency]
+	toEnd, toHasEnd := election.ConstituencyEndTimes[toConstituency]
+	fromPhase, fromHasPhase := election.ConstituencyPhases[fromConstituency]
+	toPhase, toHasPhase := election.ConstituencyPhases[toConstituency]
+	fromUsed := containsString(constituencies, fromConstituency) || fromHasEnd || fromHasPhase
+	toUsed := containsString(constituencies, toConstituency) || toHasEnd || toHasPhase
+	if !fromUsed || !toUsed {
+		return nil
+	}
+
+	if fromHasEnd != toHasEnd || !fromEnd.Equal(toEnd) {
+		return fmt.Errorf("cannot merge constituencies %s and %s, which close at different times in election %s", fromConstituency, toConstituency, election.ID)
+	}
+	if fromHasPhase != toHasPhase || fromPhase != toPhase {
+		return fmt.Errorf("cannot merge constituencies %s and %s, which poll in different phases in election %s", fromConstituency, toConstituency, election.ID)
+	}
+	return nil
+}
+
+// remapConstituency moves the declared constituency, closing time and phase
+// of fromConstituency on an election to toConstituency, keeping any setting
+// toConstituency already has. It returns true when the election changed
+func remapConstituency(election *Election, fromConstituency string, toConstituency string) bool {
+	changed := false
+	if containsString(election.Constituencies, fromConstituency) {
+		var remapped []string
+		for _, constituency := range election.Constituencies {
+			if constituency == fromConstituency {
+				constituency = toConstituency
+			}
+			if !containsString(remapped, constituency) {
+				remapped = append(remapped, constituency)
+			}
+		}
+		election.Constituencies = remapped
+		changed = true
+	}
+	if endTime, ok := election.ConstituencyEndTimes[fromConstituency]; ok {
+		if _, ok := election.ConstituencyEndTimes[toConstituency]; !ok {
+			election.ConstituencyEndTimes[toConstituency] = endTime
+		}
+		delete(election.ConstituencyEndTimes, fromConstituency)
+		changed = true
+	}
+	if phase, ok := election.ConstituencyPhases[fromConstituency]; ok {
+		if _, ok := election.ConstituencyPhases[toConstituency]; !ok {
+			election.ConstituencyPhases[toConstituency] = phase
+		}
+		delete(election.ConstituencyPhases, fromConstituency)
+		changed = true
+	}
+	return changed
+}
+
+// MergeConstituencies reassigns all voters and candidates of one constituency
+// to another and remaps the constituencies declared on elections, along with
+// their closing times and polling phases. The merge is refused when an
+// election that has not ended gives the two constituencies different closing
+// times or phases. Certified elections are immutable and keep their settings.
+// Admin only
+func (s *VotingContract) MergeConstituencies(ctx contractapi.TransactionContextInterface, fromConstituency string, toConstituency string) (*MergeReport, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromConstituency == "" || toConstituency == "" {
+		return nil, fmt.Errorf("both constituencies must be specified")
+	}
+	if fromConstituency == toConstituency {
+		return nil, fmt.Errorf("cannot merge constituency %s into itself", fromConstituency)
+	}
+
+	// An election covering either constituency, through its candidates or
+	// its declared constituencies, is affected
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, election := range elections {
+		if election.Status != "active" {
+			continue
+		}
+		constituencies, err := s.electionConstituencies(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		if containsString(constituencies, fromConstituency) || containsString(constituencies, toConstituency) {
+			return nil, fmt.Errorf("cannot merge constituencies while election %s is active", election.ID)
+		}
+	}
+	for _, election := range elections {
+		if election.Status != "created" || election.Certified {
+			continue
+		}
+		err = s.constituencySettingsConflict(ctx, election, fromConstituency, toConstituency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := MergeReport{
+		FromConstituency: fromConstituency,
+		ToConstituency:   toConstituency,
+	}
+
+	for _, election := range elections {
+		if election.Certified || !remapConstituency(election, fromConstituency, toConstituency) {
+			continue
+		}
+		err = s.putElection(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		report.ElectionsUpdated++
+	}
+
+	candidates, err := s.GetAllCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Constituency != fromConstituency {
+			continue
+		}
+		candidate.Constituency = toConstituency
+		candidateJSON, err := json.Marshal(candidate)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState("CANDIDATE_"+candidate.ID, candidateJSON)
+		if err != nil {
+			return nil, err
+		}
+		report.CandidatesMoved++
+	}
+
+	voters, err := s.getAllVoters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, voter := range voters {
+		if voter.Constituency != fromConstituency {
+			continue
+		}
+		voter.Constituency = toConstituency
+		voterJSON, err := json.Marshal(voter)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState("VOTER_"+voter.ID, voterJSON)
+		if err != nil {
+			return nil, err
+		}
+		report.VotersMoved++
+	}
+
+	return &report, nil
+}
+
+// GetConstituenciesInUse returns the sorted distinct constituencies of all registered candidates and voters
+func (s *VotingContract) GetConstituenciesInUse(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	candidates, err := s.GetAllCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	voters, err := s.getAllVoters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	constituencies := []string{}
+	add := func(constituency string) {
+		if constituency != "" && !seen[constituency] {
+			seen[constituency] = true
+			constituencies = append(constituencies, constituency)
+		}
+	}
+	for _, candidate := range candidates {
+		add(candidate.Constituency)
+	}
+	for _, voter := range voters {
+		add(voter.Constituency)
+	}
+	sort.Strings(constituencies)
+
+	return constituencies, nil
+}
+
+// GetConstituencyReadiness returns, per constituency covered by an election,
+// the number of registered and non-withdrawn candidates standing there, so
+// that constituencies without any candidate are visible
+func (s *VotingContract) GetConstituencyReadiness(ctx contractapi.TransactionContextInterface, electionID string) (map[string]int, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	readiness := make(map[string]int)
+	for _, constituency := range constituencies {
+		readiness[constituency] = 0
+	}
+	for _, candidateID := range election.Candidates {
+		if isWithdrawn(election, candidateID) {
+			continue
+		}
+		candidate, err := s.GetCandidate(ctx, candidateID)
+		if err != nil {
+			continue // Unregistered candidates are not ready to stand
+		}
+		readiness[candidate.Constituency]++
+	}
+
+	return readiness, nil
+}
+
+// GetCandidateConstituencyMap returns, per constituency, the sorted IDs of the candidates registered there
+func (s *VotingContract) GetCandidateConstituencyMap(ctx contractapi.TransactionContextInterface) (map[string][]string, error) {
+	candidates, err := s.GetAllCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string][]string)
+	for _, candidate := range candidates {
+		mapping[candidate.Constituency] = append(mapping[candidate.Constituency], candidate.ID)
+	}
+	for constituency := range mapping {
+		sort.Strings(mapping[constituency])
+	}
+
+	return mapping, nil
+}
+
+// FindOverlappingElections returns pairs of non-test elections that both
+// cover a constituency and whose voting windows overlap
+func (s *VotingContract) FindOverlappingElections(ctx contractapi.TransactionContextInterface, constituency string) ([][2]string, error) {
+	elections, err := s.GetAllElections(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var covering []*Election
+	for _, election := range elections {
+		constituencies, err := s.electionConstituencies(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		if containsString(constituencies, constituency) {
+			covering = append(covering, election)
+		}
+	}
+	sort.Slice(covering, func(i, j int) bool {
+		return covering[i].ID < covering[j].ID
+	})
+
+	overlaps := [][2]string{}
+	for i := 0; i < len(covering); i++ {
+		for j := i + 1; j < len(covering); j++ {
+			a, b := covering[i], covering[j]
+			if a.StartTime.Before(b.EndTime) && b.StartTime.Before(a.EndTime) {
+				overlaps = append(overlaps, [2]string{a.ID, b.ID})
+			}
+		}
+	}
+
+	return overlaps, nil
+}
+
+// GetEligibleCandidateIDs returns, in ballot order, the candidates a voter in
+// the given constituency can choose in an election: listed, registered in
+// that constituency and not withdrawn
+func (s *VotingContract) GetEligibleCandidateIDs(ctx contractapi.TransactionContextInterface, electionID string, constituency string) ([]string, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateIDs := []string{}
+	for _, candidateID := range election.Candidates {
+		if isWithdrawn(election, candidateID) {
+			continue
+		}
+		candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if candidateJSON == nil {
+			continue
+		}
+
+		var candidate Candidate
+		err = json.Unmarshal(candidateJSON, &candidate)
+		if err != nil {
+			return nil, err
+		}
+		if candidate.Constituency == constituency {
+			candidateIDs = append(candidateIDs, candidateID)
+		}
+	}
+
+	return candidateIDs, nil
+}
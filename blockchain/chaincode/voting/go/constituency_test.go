@@ -0,0 +1,375 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeConstituencies(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("Old", "C1")
+	env.registerCandidates("New", "C2")
+	env.registerVoters("Old", "V1", "V2")
+	env.registerVoters("New", "V3")
+	env.registerVoters("Other", "V4")
+	env.createElection("E1")
+	env.configure("E1", `{"constituencies":["Old","New"]}`)
+
+	report, err := env.contract.MergeConstituencies(env.ctx, "Old", "New")
+	must(t, err)
+	if report.VotersMoved != 2 || report.CandidatesMoved != 1 || report.ElectionsUpdated != 1 {
+		t.Fatalf("report = %+v, want 2 voters, 1 candidate and 1 election", report)
+	}
+
+	for _, voterID := range []string{"V1", "V2", "V3"} {
+		voter, err := env.contract.GetVoter(env.ctx, voterID)
+		must(t, err)
+		if voter.Constituency != "New" {
+			t.Errorf("voter %s is in %s, want New", voterID, voter.Constituency)
+		}
+	}
+	voter, err := env.contract.GetVoter(env.ctx, "V4")
+	must(t, err)
+	if voter.Constituency != "Other" {
+		t.Errorf("voter V4 was moved to %s", voter.Constituency)
+	}
+	candidate, err := env.contract.GetCandidate(env.ctx, "C1")
+	must(t, err)
+	if candidate.Constituency != "New" {
+		t.Errorf("candidate C1 is in %s, want New", candidate.Constituency)
+	}
+	if got := env.election("E1").Constituencies; len(got) != 1 || got[0] != "New" {
+		t.Errorf("election constituencies = %v, want [New]", got)
+	}
+}
+
+func TestMergeConstituenciesRefused(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(env *testEnv)
+		from  string
+		to    string
+		want  string
+	}{
+		{
+			name: "active election covers the source constituency",
+			setup: func(env *testEnv) {
+				env.createElection("E1", "C1")
+				env.setStatus("E1", "active")
+			},
+			from: "Old",
+			to:   "New",
+			want: "while election E1 is active",
+		},
+		{
+			name: "active election covers the target constituency",
+			setup: func(env *testEnv) {
+				env.createElection("E1", "C2")
+				env.setStatus("E1", "active")
+			},
+			from: "Old",
+			to:   "New",
+			want: "while election E1 is active",
+		},
+		{
+			name:  "merge into itself",
+			setup: func(env *testEnv) {},
+			from:  "Old",
+			to:    "Old",
+			want:  "into itself",
+		},
+		{
+			name:  "missing constituency",
+			setup: func(env *testEnv) {},
+			from:  "",
+			to:    "New",
+			want:  "must be specified",
+		},
+		{
+			name:  "caller is not an admin",
+			setup: func(env *testEnv) { env.asUser() },
+			from:  "Old",
+			to:    "New",
+			want:  "access denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("Old", "C1")
+			env.registerCandidates("New", "C2")
+			env.registerVoters("Old", "V1")
+			tt.setup(env)
+
+			_, err := env.contract.MergeConstituencies(env.ctx, tt.from, tt.to)
+			mustFail(t, err, tt.want)
+
+			voter, err := env.contract.GetVoter(env.ctx, "V1")
+			must(t, err)
+			if voter.Constituency != "Old" {
+				t.Errorf("voter V1 was moved to %s despite the refusal", voter.Constituency)
+			}
+		})
+	}
+}
+
+func TestGetConstituenciesInUse(t *testing.T) {
+	env := newTestEnv(t)
+
+	constituencies, err := env.contract.GetConstituenciesInUse(env.ctx)
+	must(t, err)
+	if len(constituencies) != 0 {
+		t.Errorf("constituencies = %v before any registration", constituencies)
+	}
+
+	env.registerCandidates("North", "C1", "C2")
+	env.registerCandidates("East", "C3")
+	env.registerVoters("North", "V1")
+	env.registerVoters("South", "V2", "V3")
+
+	constituencies, err = env.contract.GetConstituenciesInUse(env.ctx)
+	must(t, err)
+	if strings.Join(constituencies, ",") != "East,North,South" {
+		t.Errorf("constituencies = %v, want [East North South]", constituencies)
+	}
+}
+
+func TestMergeConstituenciesRemapsEndTimes(t *testing.T) {
+	early := testNow.Add(2 * time.Hour).Format(time.RFC3339)
+	late := testNow.Add(3 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name       string
+		candidates []string
+		endTimes   string
+		wantNew    string
+		want       string
+	}{
+		{"target not used by the election", []string{"C1"}, fmt.Sprintf(`{"Old":%q}`, early), early, ""},
+		{"same closing time", []string{"C1", "C2"}, fmt.Sprintf(`{"Old":%q,"New":%q}`, early, early), early, ""},
+		{"different closing times", []string{"C1", "C2"}, fmt.Sprintf(`{"Old":%q,"New":%q}`, early, late), "", "close at different times"},
+		{"target has no closing time", []string{"C1", "C2"}, fmt.Sprintf(`{"Old":%q}`, early), "", "close at different times"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("Old", "C1")
+			env.registerCandidates("New", "C2")
+			env.createElection("E1", tt.candidates...)
+			env.configure("E1", fmt.Sprintf(`{"constituencyEndTimes":%s}`, tt.endTimes))
+
+			_, err := env.contract.MergeConstituencies(env.ctx, "Old", "New")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			endTimes := env.election("E1").ConstituencyEndTimes
+			if _, ok := endTimes["Old"]; ok {
+				t.Errorf("closing time of Old was kept: %v", endTimes)
+			}
+			if got := endTimes["New"].Format(time.RFC3339); got != tt.wantNew {
+				t.Errorf("closing time of New = %s, want %s", got, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestGetConstituencyReadiness(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerCandidates("South", "C3")
+	env.createElection("E1", "C1", "C2", "C3", "GHOST")
+	env.configure("E1", `{"constituencies":["North","East"]}`)
+	must(t, env.contract.WithdrawCandidate(env.ctx, "E1", "C3"))
+
+	readiness, err := env.contract.GetConstituencyReadiness(env.ctx, "E1")
+	must(t, err)
+
+	want := map[string]int{"North": 2, "South": 0, "East": 0}
+	if len(readiness) != len(want) {
+		t.Errorf("readiness = %v, want %v", readiness, want)
+	}
+	for constituency, count := range want {
+		if got, ok := readiness[constituency]; !ok || got != count {
+			t.Errorf("readiness of %s = %d (present %v), want %d", constituency, got, ok, count)
+		}
+	}
+
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"constituencies":["North"," "]}`), "must not be empty")
+}
+
+func TestGetCandidateConstituencyMap(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C3", "C1")
+	env.registerCandidates("South", "C2")
+	env.registerVoters("East", "V1")
+
+	mapping, err := env.contract.GetCandidateConstituencyMap(env.ctx)
+	must(t, err)
+
+	want := map[string][]string{
+		"North": {"C1", "C3"},
+		"South": {"C2"},
+	}
+	if len(mapping) != len(want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+	for constituency, candidates := range want {
+		if strings.Join(mapping[constituency], ",") != strings.Join(candidates, ",") {
+			t.Errorf("candidates of %s = %v, want %v", constituency, mapping[constituency], candidates)
+		}
+	}
+}
+
+func TestFindOverlappingElections(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerCandidates("South", "C2")
+
+	windows := []struct {
+		id         string
+		start, end time.Duration // Relative to testNow
+		candidates []string
+	}{
+		{"A", 0, 2 * time.Hour, []string{"C1"}},
+		{"B", time.Hour, 3 * time.Hour, []string{"C1"}},
+		{"C", 2 * time.Hour, 4 * time.Hour, []string{"C1"}}, // Starts as A ends
+		{"D", 0, 4 * time.Hour, []string{"C2"}},
+		{"T", 0, 4 * time.Hour, []string{"C1"}},
+	}
+	for _, w := range windows {
+		must(t, env.contract.CreateElection(env.ctx, w.id, "Election "+w.id, "", testNow.Add(w.start).Format(time.RFC3339), testNow.Add(w.end).Format(time.RFC3339), candidatesJSON(w.candidates)))
+	}
+	env.configure("T", `{"testMode":true}`)
+
+	tests := []struct {
+		constituency string
+		want         string
+	}{
+		{"North", "[[A B] [B C]]"},
+		{"South", "[]"},
+		{"East", "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constituency, func(t *testing.T) {
+			overlaps, err := env.contract.FindOverlappingElections(env.ctx, tt.constituency)
+			must(t, err)
+			if got := fmt.Sprint(overlaps); got != tt.want {
+				t.Errorf("overlaps = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEligibleCandidateIDs(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3", "C4")
+	env.registerCandidates("South", "C5")
+	env.createElection("E1", "C3", "C1", "C2", "C5", "GHOST")
+	must(t, env.contract.WithdrawCandidate(env.ctx, "E1", "C2"))
+
+	tests := []struct {
+		constituency string
+		want         string
+	}{
+		{"North", "C3,C1"},
+		{"South", "C5"},
+		{"East", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constituency, func(t *testing.T) {
+			candidateIDs, err := env.contract.GetEligibleCandidateIDs(env.ctx, "E1", tt.constituency)
+			must(t, err)
+			if candidateIDs == nil || strings.Join(candidateIDs, ",") != tt.want {
+				t.Errorf("candidates = %#v, want %s", candidateIDs, tt.want)
+			}
+		})
+	}
+
+	_, err := env.contract.GetEligibleCandidateIDs(env.ctx, "E9", "North")
+	mustFail(t, err, "does not exist")
+}
+
+func TestMaxCandidatesPerConstituency(t *testing.T) {
+	tests := []struct {
+		name       string
+		max        int
+		candidates []string
+		want       string
+	}{
+		{"no cap", 0, []string{"N1", "N2", "N3"}, ""},
+		{"within the cap", 2, []string{"N1", "N2", "S1"}, ""},
+		{"over the cap", 2, []string{"N1", "N2", "N3", "S1"}, "constituency North exceeds the limit of 2 candidates"},
+		{"unregistered candidates not counted", 1, []string{"N1", "GHOST1", "GHOST2"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "N1", "N2", "N3")
+			env.registerCandidates("South", "S1")
+			env.setConfig(fmt.Sprintf(`{"maxCandidatesPerConstituency":%d}`, tt.max))
+
+			err := env.contract.CreateElection(env.ctx, "E1", "Election", "", testNow.Format(time.RFC3339), testNow.Add(time.Hour).Format(time.RFC3339), candidatesJSON(tt.candidates))
+			if tt.want == "" {
+				must(t, err)
+				return
+			}
+			mustFail(t, err, tt.want)
+		})
+	}
+}
+
+func TestConstituencyClosingTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		grace   int
+		after   time.Duration // Time of the votes relative to North's closing time
+		voterID string
+		want    string
+	}{
+		{"North before its closing time", 0, -time.Minute, "V1", ""},
+		{"North at its closing time", 0, 0, "V1", ""},
+		{"North after its closing time", 0, time.Minute, "V1", "polling in constituency North closed at"},
+		{"North within the grace period", 120, time.Minute, "V1", ""},
+		{"North after the grace period", 120, 3 * time.Minute, "V1", "polling in constituency North closed at"},
+		{"South still open", 0, time.Minute, "V2", ""},
+		{"East without its own closing time", 0, time.Minute, "V3", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerCandidates("South", "C2")
+			env.registerCandidates("East", "C3")
+			env.registerVoters("North", "V1")
+			env.registerVoters("South", "V2")
+			env.registerVoters("East", "V3")
+			env.createElection("E1", "C1", "C2", "C3")
+			northClose := env.now.Add(10 * time.Minute)
+			env.configure("E1", fmt.Sprintf(`{"constituencyEndTimes":{"North":%q,"South":%q},"gracePeriodSeconds":%d}`,
+				northClose.Format(time.RFC3339), env.now.Add(30*time.Minute).Format(time.RFC3339), tt.grace))
+			env.setStatus("E1", "active")
+			env.setTime(northClose.Add(tt.after))
+
+			report, err := env.contract.GetVoterEligibility(env.ctx, "E1", tt.voterID)
+			must(t, err)
+			if report.ConstituencyOpen != (tt.want == "") {
+				t.Errorf("constituency open = %v, want %v", report.ConstituencyOpen, tt.want == "")
+			}
+
+			err = env.vote("E1", tt.voterID, "C1")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+		})
+	}
+}
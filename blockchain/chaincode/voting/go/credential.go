@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// credentialKey is the transient field carrying a voter's raw credential
+const credentialKey = "credential"
+
+// credentialPepperKey is the transient field carrying the secret key that
+// credential hashes are computed with. It is shared by the clients that
+// register voters and cast credential votes, and never reaches the ledger
+const credentialPepperKey = "credentialPepper"
+
+// minCredentialPepperLength is the minimum length in bytes of a credential
+// pepper, so that stored hashes cannot be brute-forced by guessing it
+const minCredentialPepperLength = 16
+
+// VoterCredential is the keyed hash of a voter's credential. It is stored
+// under its own key so that voter queries never return it
+type VoterCredential struct {
+	VoterID string `json:"voterId"`
+	Salt    string `json:"salt"` // Per-voter salt, so that equal credentials hash differently
+	Hash    string `json:"hash"` // Hex HMAC-SHA256 of the salt and credential, keyed by the pepper
+}
+
+// credentialHash returns the hex HMAC-SHA256 of a salted voter credential,
+// keyed by the pepper. Without the pepper, a hash read from the ledger
+// cannot be checked against guessed credentials
+func credentialHash(pepper string, salt string, credential string) string {
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(salt + "|" + credential))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// getCredentialPepper returns the credential pepper supplied in the transient data
+func getCredentialPepper(ctx contractapi.TransactionContextInterface) (string, error) {
+	pepper, err := getTransientValue(ctx, credentialPepperKey)
+	if err != nil {
+		return "", err
+	}
+	if len(pepper) < minCredentialPepperLength {
+		return "", fmt.Errorf("a credential pepper of at least %d bytes must be supplied in the transient field %s", minCredentialPepperLength, credentialPepperKey)
+	}
+	return pepper, nil
+}
+
+// getVoterCredential returns the stored credential of a voter, or nil when there is none
+func getVoterCredential(ctx contractapi.TransactionContextInterface, voterID string) (*VoterCredential, error) {
+	credentialJSON, err := ctx.GetStub().GetState("CREDENTIAL_" + voterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if credentialJSON == nil {
+		return nil, nil
+	}
+
+	var credential VoterCredential
+	err = json.Unmarshal(credentialJSON, &credential)
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// checkVoterCredential checks a supplied credential against the hash stored
+// for a voter. Voters registered without a credential need none
+func checkVoterCredential(ctx contractapi.TransactionContextInterface, voter *Voter, credential string) error {
+	if !voter.HasCredential {
+		return nil
+	}
+	if credential == "" {
+		return fmt.Errorf("voter %s must supply a credential to vote", voter.ID)
+	}
+
+	stored, err := getVoterCredential(ctx, voter.ID)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		return fmt.Errorf("no credential is stored for voter %s", voter.ID)
+	}
+	pepper, err := getCredentialPepper(ctx)
+	if err != nil {
+		return err
+	}
+
+	given := credentialHash(pepper, stored.Salt, credential)
+	if !hmac.Equal([]byte(given), []byte(stored.Hash)) {
+		return fmt.Errorf("credential does not match voter %s", voter.ID)
+	}
+	return nil
+}
+
+// RegisterVoterWithCredential registers a new voter together with a keyed,
+// salted hash of their credential. The raw credential and the pepper are
+// supplied in the transient fields credential and credentialPepper, and only
+// the hash is stored
+func (s *VotingContract) RegisterVoterWithCredential(ctx contractapi.TransactionContextInterface, id string, name string, constituency string) error {
+	credential, err := getTransientValue(ctx, credentialKey)
+	if err != nil {
+		return err
+	}
+	if credential == "" {
+		return fmt.Errorf("a credential must be supplied in the transient field %s", credentialKey)
+	}
+	pepper, err := getCredentialPepper(ctx)
+	if err != nil {
+		return err
+	}
+
+	voterJSON, err := ctx.GetStub().GetState("VOTER_" + id)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if voterJSON != nil {
+		return fmt.Errorf("the voter %s already exists", id)
+	}
+
+	voterJSON, err = json.Marshal(Voter{
+		ID:            id,
+		Name:          name,
+		Constituency:  constituency,
+		HasCredential: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The salt must be the same on every endorsing peer, so it is derived
+	// from the transaction rather than drawn at random
+	salt := sha256.Sum256([]byte(ctx.GetStub().GetTxID() + "|" + id))
+	credentialRecord := VoterCredential{
+		VoterID: id,
+		Salt:    hex.EncodeToString(salt[:]),
+	}
+	credentialRecord.Hash = credentialHash(pepper, credentialRecord.Salt, credential)
+	credentialJSON, err := json.Marshal(credentialRecord)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState("CREDENTIAL_"+id, credentialJSON)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("VOTER_"+id, voterJSON)
+}
+
+// CastVoteWithCredential casts a vote after checking the credential supplied
+// in the transient field credential against the one stored for the voter,
+// using the pepper in the transient field credentialPepper. Transient data
+// keeps the raw credential out of the transaction arguments, which are
+// recorded in blocks and could be replayed. A wrong credential refuses the
+// vote like CastVote does
+func (s *VotingContract) CastVoteWithCredential(ctx contractapi.TransactionContextInterface, electionID string, voterID string, candidateID string) (*VoteOutcome, error) {
+	credential, err := getTransientValue(ctx, credentialKey)
+	if err != nil {
+		return nil, err
+	}
+	// A missing pepper is a client error, not a refused attempt
+	_, err = getCredentialPepper(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.castVote(ctx, electionID, voterID, candidateID, credential, "")
+	return voteOutcome(err)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// testPepper is the credential pepper the test clients share
+const testPepper = "a-pepper-of-the-commission"
+
+// setCredential supplies a voter credential and the test pepper as transient data
+func (e *testEnv) setCredential(credential string) {
+	e.stub.TransientMap = map[string][]byte{credentialKey: []byte(credential), credentialPepperKey: []byte(testPepper)}
+}
+
+// registerVoterWithCredential registers a voter in North with a credential
+func (e *testEnv) registerVoterWithCredential(id string, credential string) {
+	e.t.Helper()
+	e.setCredential(credential)
+	must(e.t, e.contract.RegisterVoterWithCredential(e.ctx, id, "Voter "+id, "North"))
+	e.stub.TransientMap = nil
+}
+
+// voteWithCredential casts a credential vote in its own transaction
+func (e *testEnv) voteWithCredential(electionID string, voterID string, candidateID string) error {
+	return e.submitVote(func() (*VoteOutcome, error) {
+		return e.contract.CastVoteWithCredential(e.ctx, electionID, voterID, candidateID)
+	})
+}
+
+func TestCastVoteWithCredential(t *testing.T) {
+	tests := []struct {
+		name       string
+		transient  map[string][]byte
+		withPlain  bool // Vote through CastVote instead
+		want       string
+		wantRefuse bool // The attempt is refused rather than failing
+	}{
+		{"matching credential", map[string][]byte{credentialKey: []byte("s3cret-pin"), credentialPepperKey: []byte(testPepper)}, false, "", false},
+		{"non-matching credential", map[string][]byte{credentialKey: []byte("wrong-pin"), credentialPepperKey: []byte(testPepper)}, false, "credential does not match voter V1", true},
+		{"other pepper", map[string][]byte{credentialKey: []byte("s3cret-pin"), credentialPepperKey: []byte(testPepper + "-other")}, false, "credential does not match voter V1", true},
+		{"no credential", map[string][]byte{credentialPepperKey: []byte(testPepper)}, false, "must supply a credential", true},
+		{"no pepper", map[string][]byte{credentialKey: []byte("s3cret-pin")}, false, "credential pepper of at least 16 bytes", false},
+		{"plain CastVote", nil, true, "must supply a credential", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoterWithCredential("V1", "s3cret-pin")
+			env.createElection("E1", "C1")
+			env.setStatus("E1", "active")
+
+			env.stub.TransientMap = tt.transient
+			var err error
+			if tt.withPlain {
+				err = env.vote("E1", "V1", "C1")
+			} else {
+				err = env.voteWithCredential("E1", "V1", "C1")
+			}
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				if voteJSON, _ := env.stub.GetState("VOTE_E1_V1"); voteJSON != nil {
+					t.Errorf("vote recorded despite the refusal")
+				}
+				if attemptJSON, _ := env.stub.GetState("ATTEMPT_E1_V1"); (attemptJSON != nil) != tt.wantRefuse {
+					t.Errorf("attempt recorded = %v, want %v", attemptJSON != nil, tt.wantRefuse)
+				}
+				return
+			}
+			must(t, err)
+		})
+	}
+}
+
+func TestVotersWithoutCredential(t *testing.T) {
+	env := setupElection(t)
+	env.setCredential("anything")
+	must(t, env.voteWithCredential("E1", "V1", "C1"))
+	env.mustVote("E1", "V2", "C1")
+}
+
+func TestRegisterVoterWithCredential(t *testing.T) {
+	tests := []struct {
+		name      string
+		transient map[string][]byte
+		want      string
+	}{
+		{"credential and pepper", map[string][]byte{credentialKey: []byte("pin"), credentialPepperKey: []byte(testPepper)}, ""},
+		{"no credential", map[string][]byte{credentialPepperKey: []byte(testPepper)}, "a credential must be supplied in the transient field credential"},
+		{"no pepper", map[string][]byte{credentialKey: []byte("pin")}, "credential pepper of at least 16 bytes"},
+		{"short pepper", map[string][]byte{credentialKey: []byte("pin"), credentialPepperKey: []byte("short")}, "credential pepper of at least 16 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.stub.TransientMap = tt.transient
+			err := env.contract.RegisterVoterWithCredential(env.ctx, "V1", "Voter V1", "North")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			mustFail(t, env.contract.RegisterVoterWithCredential(env.ctx, "V1", "Voter V1", "North"), "already exists")
+		})
+	}
+}
+
+func TestCredentialKeptOffVoterRecord(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerVoterWithCredential("V1", "1234")
+	env.newTx()
+	env.registerVoterWithCredential("V2", "1234")
+
+	// Voter queries only tell that a credential is required
+	voterJSON, err := env.stub.GetState("VOTER_V1")
+	must(t, err)
+	if strings.Contains(string(voterJSON), "credentialHash") || strings.Contains(string(voterJSON), "1234") {
+		t.Errorf("voter record %s carries the credential", voterJSON)
+	}
+	voters, err := env.contract.GetVotersByIDs(env.ctx, `["V1"]`)
+	must(t, err)
+	if !voters["V1"].HasCredential {
+		t.Errorf("voter V1 is not marked as requiring a credential")
+	}
+
+	// Equal credentials of different voters hash differently, and the stored
+	// hash cannot be reproduced without the pepper
+	first, err := getVoterCredential(env.ctx, "V1")
+	must(t, err)
+	second, err := getVoterCredential(env.ctx, "V2")
+	must(t, err)
+	if first.Salt == second.Salt || first.Hash == second.Hash {
+		t.Errorf("voters with the same credential share a salt or hash")
+	}
+	if credentialHash("", first.Salt, "1234") == first.Hash {
+		t.Errorf("stored hash does not depend on the pepper")
+	}
+	if credentialHash(testPepper, first.Salt, "1234") != first.Hash {
+		t.Errorf("stored hash is not the keyed hash of the credential")
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Delegation records a voter's choice of a proxy to vote on their behalf
+type Delegation struct {
+	PrincipalID string    `json:"principalId"`
+	ProxyID     string    `json:"proxyId"`
+	DelegatedAt time.Time `json:"delegatedAt"`
+}
+
+// getDelegation returns the delegation made by a voter, or nil when they have not delegated
+func (s *VotingContract) getDelegation(ctx contractapi.TransactionContextInterface, principalID string) (*Delegation, error) {
+	delegationJSON, err := ctx.GetStub().GetState("DELEGATION_" + principalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if delegationJSON == nil {
+		return nil, nil
+	}
+
+	var delegation Delegation
+	err = json.Unmarshal(delegationJSON, &delegation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delegation, nil
+}
+
+// isProxy returns true when some voter has delegated their vote to voterID
+func (s *VotingContract) isProxy(ctx contractapi.TransactionContextInterface, voterID string) (bool, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("DELEGATION_", prefixRangeEnd("DELEGATION_"))
+	if err != nil {
+		return false, err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return false, err
+		}
+
+		var delegation Delegation
+		err = json.Unmarshal(queryResponse.Value, &delegation)
+		if err != nil {
+			return false, err
+		}
+		if delegation.ProxyID == voterID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// DelegateVote records that a voter's vote may be cast by a proxy through
+// CastVoteAsProxy. Delegations cannot be chained: a proxy must not have
+// delegated their own vote, and a voter acting as a proxy cannot delegate,
+// which also rules out circular delegation
+func (s *VotingContract) DelegateVote(ctx contractapi.TransactionContextInterface, principalID string, proxyID string) error {
+	if principalID == proxyID {
+		return fmt.Errorf("voter %s cannot delegate their vote to themselves", principalID)
+	}
+
+	_, err := s.GetVoter(ctx, principalID)
+	if err != nil {
+		return err
+	}
+	_, err = s.GetVoter(ctx, proxyID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.getDelegation(ctx, principalID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("voter %s has already delegated their vote to %s", principalID, existing.ProxyID)
+	}
+
+	proxyDelegation, err := s.getDelegation(ctx, proxyID)
+	if err != nil {
+		return err
+	}
+	if proxyDelegation != nil {
+		return fmt.Errorf("voter %s has delegated their own vote and cannot act as a proxy", proxyID)
+	}
+
+	principalIsProxy, err := s.isProxy(ctx, principalID)
+	if err != nil {
+		return err
+	}
+	if principalIsProxy {
+		return fmt.Errorf("voter %s is acting as a proxy and cannot delegate their vote", principalID)
+	}
+
+	delegatedAt, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	delegationJSON, err := json.Marshal(Delegation{
+		PrincipalID: principalID,
+		ProxyID:     proxyID,
+		DelegatedAt: delegatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("DELEGATION_"+principalID, delegationJSON)
+}
+
+// CastVoteAsProxy casts a principal's vote on their behalf. The vote is
+// recorded as the principal's, who must have delegated to the proxy and meet
+// every check CastVote applies. Principals registered with a credential hash
+// cannot vote by proxy
+func (s *VotingContract) CastVoteAsProxy(ctx contractapi.TransactionContextInterface, electionID string, proxyID string, principalID string, candidateID string) (*VoteOutcome, error) {
+	delegation, err := s.getDelegation(ctx, principalID)
+	if err != nil {
+		return nil, err
+	}
+	if delegation == nil || delegation.ProxyID != proxyID {
+		return nil, fmt.Errorf("voter %s has not delegated their vote to %s", principalID, proxyID)
+	}
+
+	_, err = s.castVote(ctx, electionID, principalID, candidateID, "", proxyID)
+	return voteOutcome(err)
+}
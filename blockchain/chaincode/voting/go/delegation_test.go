@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+// voteAsProxy casts a principal's vote through their proxy in its own transaction
+func (e *testEnv) voteAsProxy(electionID string, proxyID string, principalID string, candidateID string) error {
+	return e.submitVote(func() (*VoteOutcome, error) {
+		return e.contract.CastVoteAsProxy(e.ctx, electionID, proxyID, principalID, candidateID)
+	})
+}
+
+func TestDelegateVote(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  [][2]string // Delegations made beforehand, principal then proxy
+		principal string
+		proxy     string
+		want      string
+	}{
+		{"valid delegation", nil, "V1", "V2", ""},
+		{"to themselves", nil, "V1", "V1", "cannot delegate their vote to themselves"},
+		{"unknown principal", nil, "V9", "V2", "does not exist"},
+		{"unknown proxy", nil, "V1", "V9", "does not exist"},
+		{"already delegated", [][2]string{{"V1", "V3"}}, "V1", "V2", "has already delegated their vote to V3"},
+		{"proxy has delegated", [][2]string{{"V2", "V3"}}, "V1", "V2", "cannot act as a proxy"},
+		{"principal is a proxy", [][2]string{{"V3", "V1"}}, "V1", "V2", "is acting as a proxy and cannot delegate"},
+		{"circular delegation", [][2]string{{"V2", "V1"}}, "V1", "V2", "voter V2 has delegated their own vote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			for _, delegation := range tt.existing {
+				must(t, env.contract.DelegateVote(env.ctx, delegation[0], delegation[1]))
+			}
+
+			err := env.contract.DelegateVote(env.ctx, tt.principal, tt.proxy)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+		})
+	}
+}
+
+func TestCastVoteAsProxy(t *testing.T) {
+	env := setupElection(t)
+	must(t, env.contract.DelegateVote(env.ctx, "V1", "V2"))
+
+	mustFail(t, env.voteAsProxy("E1", "V3", "V1", "C1"), "voter V1 has not delegated their vote to V3")
+	mustFail(t, env.voteAsProxy("E1", "V2", "V4", "C1"), "voter V4 has not delegated their vote to V2")
+
+	must(t, env.voteAsProxy("E1", "V2", "V1", "C2"))
+	vote, err := env.contract.GetVote(env.ctx, "E1", "V1")
+	must(t, err)
+	if vote.VoterID != "V1" || vote.ProxyID != "V2" || vote.CandidateID != "C2" {
+		t.Errorf("vote = %+v, want V1's vote for C2 cast by V2", vote)
+	}
+
+	// The principal's vote is used up, while the proxy keeps their own
+	mustFail(t, env.vote("E1", "V1", "C1"), "already cast a vote")
+	mustFail(t, env.voteAsProxy("E1", "V2", "V1", "C1"), "already cast a vote")
+	env.mustVote("E1", "V2", "C1")
+
+	env.endElection("E1")
+	if votes := candidateVotes(env.results("E1")); votes["C1"] != 1 || votes["C2"] != 1 {
+		t.Errorf("votes = %v, want one each", votes)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// EligibilityReport explains whether a voter can currently vote in an
+// election and, if not, every reason why
+type EligibilityReport struct {
+	ElectionID          string   `json:"electionId"`
+	VoterID             string   `json:"voterId"`
+	Eligible            bool     `json:"eligible"`
+	ElectionActive      bool     `json:"electionActive"`
+	WithinWindow        bool     `json:"withinWindow"`
+	VoterExists         bool     `json:"voterExists"`
+	ConstituencyCovered bool     `json:"constituencyCovered"` // Always true when the election covers no constituency
+	AlreadyVoted        bool     `json:"alreadyVoted"`        // In this election
+	OnVoterRoll         bool     `json:"onVoterRoll"`         // Always true while the roll is not frozen
+	CheckedIn           bool     `json:"checkedIn"`           // Always true when check-in is not required
+	PhaseOpen           bool     `json:"phaseOpen"`           // Always true when polling is not phased
+	ConstituencyOpen    bool     `json:"constituencyOpen"`    // Always true when the voter's constituency has no closing time of its own
+	ParticipatedInPrior bool     `json:"participatedInPrior"` // Always true when no prior election is required
+	CapacityReached     bool     `json:"capacityReached"`
+	CoolingDown         bool     `json:"coolingDown"`
+	Reasons             []string `json:"reasons"` // In the order CastVote checks them
+}
+
+// votingDeadline returns the last moment a vote is accepted, including any grace period
+func votingDeadline(election *Election) time.Time {
+	return election.EndTime.Add(time.Duration(election.GracePeriodSeconds) * time.Second)
+}
+
+// constituencyOpen returns false once the staggered closing time of a
+// constituency, extended by the grace period, has passed
+func constituencyOpen(election *Election, constituency string, now time.Time) bool {
+	endTime, ok := election.ConstituencyEndTimes[constituency]
+	if !ok {
+		return true
+	}
+	return !now.After(endTime.Add(time.Duration(election.GracePeriodSeconds) * time.Second))
+}
+
+// evaluateEligibility runs every voter-side precondition of CastVote at the
+// given time. It returns the registered voter when one exists
+func (s *VotingContract) evaluateEligibility(ctx contractapi.TransactionContextInterface, election *Election, voterID string, now time.Time) (*EligibilityReport, *Voter, error) {
+	report := EligibilityReport{
+		ElectionID: election.ID,
+		VoterID:    voterID,
+		Reasons:    []string{},
+	}
+
+	report.ElectionActive = election.Status == "active"
+	if !report.ElectionActive {
+		report.Reasons = append(report.Reasons, "election is not active")
+	}
+
+	report.WithinWindow = !now.Before(election.StartTime) && !now.After(votingDeadline(election))
+	if !report.WithinWindow {
+		report.Reasons = append(report.Reasons, "election is not currently open for voting")
+	}
+
+	full, err := s.capacityReached(ctx, election)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.CapacityReached = full
+	if full {
+		report.Reasons = append(report.Reasons, "voting capacity reached")
+	}
+
+	voterJSON, err := ctx.GetStub().GetState("VOTER_" + voterID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if voterJSON == nil {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("the voter %s does not exist", voterID))
+		return &report, nil, nil
+	}
+	report.VoterExists = true
+
+	var voter Voter
+	err = json.Unmarshal(voterJSON, &voter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.ConstituencyCovered = len(constituencies) == 0 || containsString(constituencies, voter.Constituency)
+	if !report.ConstituencyCovered {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("voter %s is registered in constituency %s, which election %s does not cover", voterID, voter.Constituency, election.ID))
+	}
+
+	// Voting is tracked per election, so that voting in one election does
+	// not bar the voter from another, such as a runoff
+	voteJSON, err := ctx.GetStub().GetState("VOTE_" + election.ID + "_" + voterID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	report.AlreadyVoted = voteJSON != nil
+	if report.AlreadyVoted {
+		report.Reasons = append(report.Reasons, "voter has already cast a vote")
+	}
+
+	// Reject rapid retries before reporting anything else about the voter,
+	// after the distinct "already voted" error
+	coolingDown, cooldownSeconds, err := s.inVoteCooldown(ctx, election.ID, voterID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.CoolingDown = coolingDown
+	if coolingDown {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("too many attempts: voter %s must wait %d seconds between vote attempts in an election", voterID, cooldownSeconds))
+	}
+
+	report.PhaseOpen = isPhaseOpen(election, voter.Constituency)
+	if !report.PhaseOpen {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("polling phase for constituency %s is not open", voter.Constituency))
+	}
+
+	report.ConstituencyOpen = constituencyOpen(election, voter.Constituency, now)
+	if !report.ConstituencyOpen {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("polling in constituency %s closed at %s", voter.Constituency, election.ConstituencyEndTimes[voter.Constituency].Format(time.RFC3339)))
+	}
+
+	roll, err := s.getVoterRoll(ctx, election.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.OnVoterRoll = roll == nil || roll.includes(voterID)
+	if !report.OnVoterRoll {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("voter %s is not on the frozen voter roll for this election", voterID))
+	}
+
+	report.CheckedIn = true
+	if election.RequireCheckIn {
+		report.CheckedIn, err = s.isCheckedIn(ctx, election.ID, voterID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !report.CheckedIn {
+			report.Reasons = append(report.Reasons, fmt.Sprintf("voter %s has not checked in for this election", voterID))
+		}
+	}
+
+	report.ParticipatedInPrior = true
+	if election.RequirePriorParticipationIn != "" {
+		priorVoteJSON, err := ctx.GetStub().GetState("VOTE_" + election.RequirePriorParticipationIn + "_" + voterID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		report.ParticipatedInPrior = priorVoteJSON != nil
+		if !report.ParticipatedInPrior {
+			report.Reasons = append(report.Reasons, fmt.Sprintf("voter %s did not vote in prerequisite election %s", voterID, election.RequirePriorParticipationIn))
+		}
+	}
+
+	report.Eligible = len(report.Reasons) == 0
+
+	return &report, &voter, nil
+}
+
+// GetVoterEligibility reports whether a voter can currently vote in an
+// election and every reason CastVote would reject them
+func (s *VotingContract) GetVoterEligibility(ctx contractapi.TransactionContextInterface, electionID string, voterID string) (*EligibilityReport, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report, _, err := s.evaluateEligibility(ctx, election, voterID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// VoteCheck tells a client whether a voter can currently vote and, if not, why
+type VoteCheck struct {
+	CanVote bool   `json:"canVote"`
+	Reason  string `json:"reason"` // The error CastVote would return, empty when CanVote is set
+}
+
+// CanVoterVote reports whether a voter can currently cast a ballot in an
+// election and, if not, the first reason CastVote would give
+func (s *VotingContract) CanVoterVote(ctx contractapi.TransactionContextInterface, electionID string, voterID string) (*VoteCheck, error) {
+	report, err := s.GetVoterEligibility(ctx, electionID, voterID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := VoteCheck{
+		CanVote: report.Eligible,
+	}
+	if !report.Eligible {
+		check.Reason = report.Reasons[0]
+	}
+
+	return &check, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetVoterEligibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(env *testEnv)
+		voterID     string
+		wantReasons []string
+		check       func(report *EligibilityReport) bool
+	}{
+		{
+			name: "eligible",
+			setup: func(env *testEnv) {
+				must(env.t, env.contract.CheckInVoter(env.ctx, "E1", "V1"))
+			},
+			voterID: "V1",
+			check: func(r *EligibilityReport) bool {
+				return r.ElectionActive && r.WithinWindow && r.VoterExists && r.ConstituencyCovered && r.OnVoterRoll && r.CheckedIn
+			},
+		},
+		{
+			name:        "unregistered voter",
+			setup:       func(env *testEnv) {},
+			voterID:     "V9",
+			wantReasons: []string{"the voter V9 does not exist"},
+			check:       func(r *EligibilityReport) bool { return !r.VoterExists },
+		},
+		{
+			name: "every voter-side reason at once",
+			setup: func(env *testEnv) {
+				env.registerVoters("South", "S1")
+				must(env.t, env.contract.FreezeVoterRoll(env.ctx, "E1"))
+			},
+			voterID: "S1",
+			wantReasons: []string{
+				"which election E1 does not cover",
+				"not on the frozen voter roll",
+				"has not checked in",
+			},
+			check: func(r *EligibilityReport) bool {
+				return r.VoterExists && !r.ConstituencyCovered && !r.OnVoterRoll && !r.CheckedIn
+			},
+		},
+		{
+			name: "election closed and voter already voted",
+			setup: func(env *testEnv) {
+				must(env.t, env.contract.CheckInVoter(env.ctx, "E1", "V1"))
+				env.mustVote("E1", "V1", "C1")
+				env.endElection("E1")
+				env.advance(2 * time.Hour)
+			},
+			voterID: "V1",
+			wantReasons: []string{
+				"election is not active",
+				"election is not currently open for voting",
+				"voter has already cast a vote",
+			},
+			check: func(r *EligibilityReport) bool {
+				return !r.ElectionActive && !r.WithinWindow && r.AlreadyVoted && r.CheckedIn
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1")
+			env.createElection("E1", "C1")
+			env.configure("E1", `{"requireCheckIn":true}`)
+			env.setStatus("E1", "active")
+			tt.setup(env)
+
+			report, err := env.contract.GetVoterEligibility(env.ctx, "E1", tt.voterID)
+			must(t, err)
+			if report.Eligible != (len(tt.wantReasons) == 0) {
+				t.Errorf("eligible = %v with reasons %v", report.Eligible, report.Reasons)
+			}
+			if len(report.Reasons) != len(tt.wantReasons) {
+				t.Fatalf("reasons = %q, want %d matching %q", report.Reasons, len(tt.wantReasons), tt.wantReasons)
+			}
+			for i, want := range tt.wantReasons {
+				if !strings.Contains(report.Reasons[i], want) {
+					t.Errorf("reason %d = %q, want it to mention %q", i, report.Reasons[i], want)
+				}
+			}
+			if !tt.check(report) {
+				t.Errorf("report flags = %+v", report)
+			}
+
+			check, err := env.contract.CanVoterVote(env.ctx, "E1", tt.voterID)
+			must(t, err)
+			if check.CanVote != report.Eligible || (!check.CanVote && check.Reason != report.Reasons[0]) {
+				t.Errorf("CanVoterVote = %+v, want the first reason of %q", check, report.Reasons)
+			}
+
+			// CastVote rejects with the first reason reported
+			if !report.Eligible && report.VoterExists {
+				env.advance(time.Minute)
+				mustFail(t, env.vote("E1", tt.voterID, "C1"), report.Reasons[0])
+			}
+		})
+	}
+}
+
+func TestCanVoterVote(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+	env.createElection("PENDING", "C1")
+
+	tests := []struct {
+		name       string
+		electionID string
+		voterID    string
+		wantReason string
+	}{
+		{"can vote", "E1", "V2", ""},
+		{"already voted", "E1", "V1", "voter has already cast a vote"},
+		{"unregistered voter", "E1", "V9", "the voter V9 does not exist"},
+		{"election not started", "PENDING", "V2", "election is not active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := env.contract.CanVoterVote(env.ctx, tt.electionID, tt.voterID)
+			must(t, err)
+			if check.CanVote != (tt.wantReason == "") || check.Reason != tt.wantReason {
+				t.Errorf("check = %+v, want reason %q", check, tt.wantReason)
+			}
+		})
+	}
+
+	_, err := env.contract.CanVoterVote(env.ctx, "E9", "V2")
+	mustFail(t, err, "does not exist")
+}
+
+func TestRequirePriorParticipation(t *testing.T) {
+	tests := []struct {
+		name    string
+		voterID string
+		want    string
+	}{
+		{"voted in the first round", "V1", ""},
+		{"voted NOTA in the first round", "V2", ""},
+		{"did not vote in the first round", "V3", "voter V3 did not vote in prerequisite election ROUND1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			env.registerVoters("North", "V1", "V2", "V3")
+			env.createElection("ROUND1", "C1", "C2")
+			env.configure("ROUND1", `{"allowNota":true}`)
+			env.setStatus("ROUND1", "active")
+			env.mustVote("ROUND1", "V1", "C1")
+			env.mustVote("ROUND1", "V2", SelectionNOTA)
+			env.endElection("ROUND1")
+
+			env.createElection("RUNOFF", "C1", "C2")
+			env.configure("RUNOFF", `{"requirePriorParticipationIn":" ROUND1 "}`)
+			env.setStatus("RUNOFF", "active")
+
+			report, err := env.contract.GetVoterEligibility(env.ctx, "RUNOFF", tt.voterID)
+			must(t, err)
+			if report.ParticipatedInPrior != (tt.want == "") || report.Eligible != (tt.want == "") {
+				t.Errorf("participated = %v, eligible = %v, want %v", report.ParticipatedInPrior, report.Eligible, tt.want == "")
+			}
+
+			err = env.vote("RUNOFF", tt.voterID, "C2")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+		})
+	}
+}
+
+func TestRequirePriorParticipationValidated(t *testing.T) {
+	env := newTestEnv(t)
+	env.createElection("E1")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"requirePriorParticipationIn":"E1"}`), "cannot require participation in itself")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"requirePriorParticipationIn":"MISSING"}`), "prerequisite election MISSING does not exist")
+
+	// Without a prerequisite every voter participated
+	env.registerVoters("North", "V1")
+	report, err := env.contract.GetVoterEligibility(env.ctx, "E1", "V1")
+	must(t, err)
+	if !report.ParticipatedInPrior {
+		t.Errorf("participation required without a prerequisite election")
+	}
+}
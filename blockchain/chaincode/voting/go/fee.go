@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// NominationFee records the nomination fee a candidate paid to stand in an election
+type NominationFee struct {
+	ElectionID        string    `json:"electionId"`
+	CandidateID       string    `json:"candidateId"`
+	NominationFeePaid bool      `json:"nominationFeePaid"`
+	FeeAmount         int       `json:"feeAmount"`
+	RecordedAt        time.Time `json:"recordedAt"`
+}
+
+// nominationFeeKey returns the world state key of a nomination fee
+func nominationFeeKey(electionID string, candidateID string) string {
+	return "FEE_" + electionID + "_" + candidateID
+}
+
+// getNominationFee returns the fee a candidate paid for an election, or nil when none was recorded
+func (s *VotingContract) getNominationFee(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) (*NominationFee, error) {
+	feeJSON, err := ctx.GetStub().GetState(nominationFeeKey(electionID, candidateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if feeJSON == nil {
+		return nil, nil
+	}
+
+	var fee NominationFee
+	err = json.Unmarshal(feeJSON, &fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fee, nil
+}
+
+// RecordNominationFee records the nomination fee paid by a candidate who is
+// on the ballot of an election or has a pending nomination for it. A fee can
+// only be recorded once per candidate and election. Admin only
+func (s *VotingContract) RecordNominationFee(ctx contractapi.TransactionContextInterface, electionID string, candidateID string, amount int) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	err = validatePositiveInt("amount", amount)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	err = checkNotCertified(election)
+	if err != nil {
+		return err
+	}
+
+	if !containsString(election.Candidates, candidateID) {
+		nomination, err := s.getNomination(ctx, electionID, candidateID)
+		if err != nil {
+			return err
+		}
+		if nomination == nil || nomination.Status != NominationPending {
+			return fmt.Errorf("candidate %s is not standing in election %s", candidateID, electionID)
+		}
+	}
+
+	existing, err := s.getNominationFee(ctx, electionID, candidateID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("a nomination fee for candidate %s in election %s has already been recorded", candidateID, electionID)
+	}
+
+	recordedAt, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	feeJSON, err := json.Marshal(NominationFee{
+		ElectionID:        electionID,
+		CandidateID:       candidateID,
+		NominationFeePaid: true,
+		FeeAmount:         amount,
+		RecordedAt:        recordedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(nominationFeeKey(electionID, candidateID), feeJSON)
+}
+
+// reportNominationFees adds each candidate's nomination fee to a result. A
+// paid fee is refunded unless the candidate forfeits their deposit, as
+// reported by GetDepositForfeitures
+func (s *VotingContract) reportNominationFees(ctx contractapi.TransactionContextInterface, election *Election, result *ElectionResult) error {
+	threshold, err := depositThreshold(ctx, result)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.CandidateResults {
+		candidateResult := &result.CandidateResults[i]
+		fee, err := s.getNominationFee(ctx, election.ID, candidateResult.CandidateID)
+		if err != nil {
+			return err
+		}
+		if fee == nil {
+			continue
+		}
+
+		forfeited := containsString(election.Candidates, candidateResult.CandidateID) && float64(candidateResult.VoteCount) < threshold
+		candidateResult.NominationFeePaid = fee.NominationFeePaid
+		candidateResult.FeeAmount = fee.FeeAmount
+		candidateResult.FeeRefunded = fee.NominationFeePaid && !forfeited
+	}
+
+	return nil
+}
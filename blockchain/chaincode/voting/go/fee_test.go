@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestRecordNominationFee(t *testing.T) {
+	tests := []struct {
+		name      string
+		as        func(env *testEnv)
+		candidate string
+		amount    int
+		want      string
+	}{
+		{"listed candidate", func(env *testEnv) {}, "C1", 100, ""},
+		{"pending nominee", func(env *testEnv) {}, "C3", 100, ""},
+		{"non-admin", func(env *testEnv) { env.asUser() }, "C1", 100, "access denied"},
+		{"zero amount", func(env *testEnv) {}, "C1", 0, "amount"},
+		{"negative amount", func(env *testEnv) {}, "C1", -5, "amount"},
+		{"candidate not standing", func(env *testEnv) {}, "C4", 100, "candidate C4 is not standing in election E1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3", "C4")
+			env.createElection("E1", "C1", "C2")
+			must(t, env.contract.NominateCandidate(env.ctx, "E1", "C3"))
+			tt.as(env)
+
+			err := env.contract.RecordNominationFee(env.ctx, "E1", tt.candidate, tt.amount)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			mustFail(t, env.contract.RecordNominationFee(env.ctx, "E1", tt.candidate, tt.amount), "has already been recorded")
+		})
+	}
+}
+
+func TestNominationFeesReportedWithResults(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6")
+	env.createElection("E1", "C1", "C2", "C3")
+	must(t, env.contract.RecordNominationFee(env.ctx, "E1", "C1", 100))
+	must(t, env.contract.RecordNominationFee(env.ctx, "E1", "C3", 50))
+	env.setStatus("E1", "active")
+	// C3 gets no votes and forfeits its deposit; C2 paid no fee
+	for _, voterID := range []string{"V1", "V2", "V3", "V4", "V5"} {
+		env.mustVote("E1", voterID, "C1")
+	}
+	env.mustVote("E1", "V6", "C2")
+	env.endElection("E1")
+
+	want := map[string]struct {
+		paid     bool
+		amount   int
+		refunded bool
+	}{
+		"C1": {true, 100, true},
+		"C2": {false, 0, false},
+		"C3": {true, 50, false},
+	}
+	for _, candidateResult := range env.results("E1").CandidateResults {
+		w := want[candidateResult.CandidateID]
+		if candidateResult.NominationFeePaid != w.paid || candidateResult.FeeAmount != w.amount || candidateResult.FeeRefunded != w.refunded {
+			t.Errorf("%s: paid %v, amount %d, refunded %v; want %v, %d, %v", candidateResult.CandidateID,
+				candidateResult.NominationFeePaid, candidateResult.FeeAmount, candidateResult.FeeRefunded, w.paid, w.amount, w.refunded)
+		}
+	}
+
+	_, err := env.contract.FinalizeResults(env.ctx, "E1")
+	must(t, err)
+	mustFail(t, env.contract.RecordNominationFee(env.ctx, "E1", "C2", 100), "certified")
+}
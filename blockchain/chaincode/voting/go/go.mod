@@ -3,6 +3,6 @@ module github.com/hyperledger/fabric-samples/chaincode/voting
 go 1.16
 
 require (
-	github.com/hyperledger/fabric-chaincode-go v0.0.0-20220720122508-9207360bbddd // indirect
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20220720122508-9207360bbddd
 	github.com/hyperledger/fabric-contract-api-go v1.1.1
 )
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// IdempotencyRecord maps a client-supplied idempotency key to the request it was first used with
+type IdempotencyRecord struct {
+	Key         string `json:"key"`
+	ElectionID  string `json:"electionId"`
+	RequestHash string `json:"requestHash"`
+}
+
+// CreateElectionWithKey creates a new election like CreateElection, but
+// retrying with the same idempotency key and identical parameters succeeds
+// instead of failing with "already exists"
+func (s *VotingContract) CreateElectionWithKey(ctx contractapi.TransactionContextInterface, idempotencyKey string, id string, name string, description string, startTimeStr string, endTimeStr string, candidatesJSON string) error {
+	if idempotencyKey == "" {
+		return fmt.Errorf("idempotency key must not be empty")
+	}
+
+	requestJSON, err := json.Marshal([]string{id, name, description, startTimeStr, endTimeStr, candidatesJSON})
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(requestJSON)
+	requestHash := hex.EncodeToString(hash[:])
+
+	recordKey := "IDEMPOTENCY_" + idempotencyKey
+	recordJSON, err := ctx.GetStub().GetState(recordKey)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if recordJSON != nil {
+		var record IdempotencyRecord
+		err = json.Unmarshal(recordJSON, &record)
+		if err != nil {
+			return err
+		}
+		if record.RequestHash != requestHash {
+			return fmt.Errorf("idempotency key %s was already used with different parameters", idempotencyKey)
+		}
+		return nil
+	}
+
+	err = s.CreateElection(ctx, id, name, description, startTimeStr, endTimeStr, candidatesJSON)
+	if err != nil {
+		return err
+	}
+
+	record := IdempotencyRecord{
+		Key:         idempotencyKey,
+		ElectionID:  id,
+		RequestHash: requestHash,
+	}
+	recordJSON, err = json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(recordKey, recordJSON)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateElectionWithKey(t *testing.T) {
+	start := testNow.Format(time.RFC3339)
+	end := testNow.Add(time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name  string
+		key   string
+		id    string
+		title string
+		want  string
+	}{
+		{"identical retry", "K1", "E1", "First", ""},
+		{"retry with different parameters", "K1", "E1", "Renamed", "already used with different parameters"},
+		{"new key for an existing election", "K2", "E1", "First", "already exists"},
+		{"empty key", "", "E2", "Second", "must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			must(t, env.contract.CreateElectionWithKey(env.ctx, "K1", "E1", "First", "", start, end, "[]"))
+
+			err := env.contract.CreateElectionWithKey(env.ctx, tt.key, tt.id, tt.title, "", start, end, "[]")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+			} else {
+				must(t, err)
+			}
+			if election := env.election("E1"); election.Name != "First" || election.Version != 1 {
+				t.Errorf("election = %s at version %d, want the original at version 1", election.Name, election.Version)
+			}
+		})
+	}
+}
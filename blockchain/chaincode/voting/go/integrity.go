@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Kinds of referential integrity violation reported by ValidateLedgerIntegrity
+const (
+	ViolationOrphanedVote          = "orphaned_vote"          // Vote for an election that does not exist
+	ViolationVoteUnknownVoter      = "vote_unknown_voter"     // Vote by a voter that is not registered
+	ViolationVoteUnknownCandidate  = "vote_unknown_candidate" // Vote for a candidate that is not registered
+	ViolationUnregisteredCandidate = "unregistered_candidate" // Election listing a candidate that is not registered
+	ViolationUnreadableVote        = "unreadable_vote"        // VOTE_ record that cannot be decoded
+)
+
+// IntegrityViolation describes one broken reference in the world state
+type IntegrityViolation struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"` // World state key of the offending record
+	Detail string `json:"detail"`
+}
+
+// IntegrityReport is the outcome of a full referential integrity sweep
+type IntegrityReport struct {
+	Valid         bool                 `json:"valid"`
+	ElectionsSeen int                  `json:"electionsSeen"`
+	VotesSeen     int                  `json:"votesSeen"`
+	Violations    []IntegrityViolation `json:"violations"`
+}
+
+// isReservedSelection returns true for ballot selections that do not name a registered candidate
+func isReservedSelection(candidateID string) bool {
+	return candidateID == SelectionNOTA || candidateID == SelectionBlank || candidateID == SelectionSpoiled || strings.HasPrefix(candidateID, writeInPrefix) || isReferendumOption(candidateID)
+}
+
+// ValidateLedgerIntegrity sweeps the world state and reports every vote that
+// references a missing election, voter or candidate, and every election that
+// lists an unregistered candidate
+func (s *VotingContract) ValidateLedgerIntegrity(ctx contractapi.TransactionContextInterface) (*IntegrityReport, error) {
+	report := IntegrityReport{
+		Violations: []IntegrityViolation{},
+	}
+
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	electionIDs := make(map[string]bool)
+	for _, election := range elections {
+		electionIDs[election.ID] = true
+	}
+	report.ElectionsSeen = len(elections)
+
+	candidates, err := s.GetAllCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidateIDs := make(map[string]bool)
+	for _, candidate := range candidates {
+		candidateIDs[candidate.ID] = true
+	}
+
+	voters, err := s.getAllVoters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	voterIDs := make(map[string]bool)
+	for _, voter := range voters {
+		voterIDs[voter.ID] = true
+	}
+
+	for _, election := range elections {
+		for _, candidateID := range election.Candidates {
+			if !candidateIDs[candidateID] {
+				report.Violations = append(report.Violations, IntegrityViolation{
+					Kind:   ViolationUnregisteredCandidate,
+					Key:    election.ID,
+					Detail: fmt.Sprintf("election %s lists unregistered candidate %s", election.ID, candidateID),
+				})
+			}
+		}
+	}
+
+	voteIterator, err := ctx.GetStub().GetStateByRange("VOTE_", prefixRangeEnd("VOTE_"))
+	if err != nil {
+		return nil, err
+	}
+	defer voteIterator.Close()
+
+	for voteIterator.HasNext() {
+		queryResponse, err := voteIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		report.VotesSeen++
+
+		var vote Vote
+		err = json.Unmarshal(queryResponse.Value, &vote)
+		if err != nil {
+			report.Violations = append(report.Violations, IntegrityViolation{
+				Kind:   ViolationUnreadableVote,
+				Key:    queryResponse.Key,
+				Detail: fmt.Sprintf("vote record cannot be decoded: %v", err),
+			})
+			continue
+		}
+
+		if !electionIDs[vote.ElectionID] {
+			report.Violations = append(report.Violations, IntegrityViolation{
+				Kind:   ViolationOrphanedVote,
+				Key:    queryResponse.Key,
+				Detail: fmt.Sprintf("vote references missing election %s", vote.ElectionID),
+			})
+		}
+		if !voterIDs[vote.VoterID] {
+			report.Violations = append(report.Violations, IntegrityViolation{
+				Kind:   ViolationVoteUnknownVoter,
+				Key:    queryResponse.Key,
+				Detail: fmt.Sprintf("vote references unregistered voter %s", vote.VoterID),
+			})
+		}
+		if !isReservedSelection(vote.CandidateID) && !candidateIDs[vote.CandidateID] {
+			report.Violations = append(report.Violations, IntegrityViolation{
+				Kind:   ViolationVoteUnknownCandidate,
+				Key:    queryResponse.Key,
+				Detail: fmt.Sprintf("vote references unregistered candidate %s", vote.CandidateID),
+			})
+		}
+	}
+
+	report.Valid = len(report.Violations) == 0
+
+	return &report, nil
+}
+
+// voterHash returns a hash identifying a voter within an election without revealing their ID
+func voterHash(electionID string, voterID string) string {
+	hash := sha256.Sum256([]byte(electionID + "|" + voterID))
+	return hex.EncodeToString(hash[:])
+}
+
+// FindDuplicateVotes groups the vote records of an election by voter hash and
+// returns the sorted hashes of voters with more than one record. Any result
+// means a double-voting guard has failed. Admin or observer only
+func (s *VotingContract) FindDuplicateVotes(ctx contractapi.TransactionContextInterface, electionID string) ([]string, error) {
+	err := requireAdminOrObserver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]int)
+	for _, vote := range votes {
+		records[voterHash(electionID, vote.VoterID)]++
+	}
+
+	duplicates := []string{}
+	for hash, count := range records {
+		if count > 1 {
+			duplicates = append(duplicates, hash)
+		}
+	}
+	sort.Strings(duplicates)
+
+	return duplicates, nil
+}
+
+// GetVoteTransactionIDs returns the sorted IDs of the transactions that
+// recorded the votes of an election, for cross-referencing with block data.
+// Votes recorded before transaction IDs were stored are left out. Admin or observer only
+func (s *VotingContract) GetVoteTransactionIDs(ctx contractapi.TransactionContextInterface, electionID string) ([]string, error) {
+	err := requireAdminOrObserver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	txIDs := []string{}
+	for _, vote := range votes {
+		if vote.TxID != "" {
+			txIDs = append(txIDs, vote.TxID)
+		}
+	}
+	sort.Strings(txIDs)
+
+	return txIDs, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidateLedgerIntegrity(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(env *testEnv)
+		want   map[string]string // Violation kind to the key it reports
+	}{
+		{"clean ledger", func(env *testEnv) {}, map[string]string{}},
+		{"vote for a missing election", func(env *testEnv) {
+			voteJSON, err := json.Marshal(Vote{ElectionID: "GONE", VoterID: "V1", CandidateID: "C1"})
+			must(env.t, err)
+			must(env.t, env.stub.PutState("VOTE_GONE_V1", voteJSON))
+		}, map[string]string{ViolationOrphanedVote: "VOTE_GONE_V1"}},
+		{"vote by a deleted voter", func(env *testEnv) {
+			must(env.t, env.stub.DelState("VOTER_V1"))
+		}, map[string]string{ViolationVoteUnknownVoter: "VOTE_E1_V1"}},
+		{"vote for a deleted candidate", func(env *testEnv) {
+			must(env.t, env.stub.DelState("CANDIDATE_C1"))
+		}, map[string]string{ViolationVoteUnknownCandidate: "VOTE_E1_V1", ViolationUnregisteredCandidate: "E1"}},
+		{"election listing an unregistered candidate", func(env *testEnv) {
+			env.createElection("E2", "C1", "GHOST")
+		}, map[string]string{ViolationUnregisteredCandidate: "E2"}},
+		{"unreadable vote", func(env *testEnv) {
+			must(env.t, env.stub.PutState("VOTE_E1_V3", []byte("{")))
+		}, map[string]string{ViolationUnreadableVote: "VOTE_E1_V3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1", "V2")
+			env.createElection("E1", "C1")
+			env.configure("E1", `{"allowNota":true}`)
+			env.setStatus("E1", "active")
+			env.mustVote("E1", "V1", "C1")
+			// Reserved selections name no candidate and are not violations
+			env.mustVote("E1", "V2", SelectionNOTA)
+			tt.tamper(env)
+
+			report, err := env.contract.ValidateLedgerIntegrity(env.ctx)
+			must(t, err)
+			if report.Valid != (len(tt.want) == 0) {
+				t.Errorf("valid = %v with violations %+v", report.Valid, report.Violations)
+			}
+			if len(report.Violations) != len(tt.want) {
+				t.Fatalf("got %d violations %+v, want %d", len(report.Violations), report.Violations, len(tt.want))
+			}
+			for _, violation := range report.Violations {
+				key, ok := tt.want[violation.Kind]
+				if !ok || violation.Key != key {
+					t.Errorf("unexpected violation %+v", violation)
+				}
+				if violation.Detail == "" {
+					t.Errorf("violation %s has no detail", violation.Kind)
+				}
+			}
+			if report.VotesSeen < 2 {
+				t.Errorf("votes seen = %d, want at least 2", report.VotesSeen)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateVotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		duplicates map[string]string // Injected key to the voter it duplicates
+		want       []string
+	}{
+		{"no duplicates", nil, []string{}},
+		{"one duplicate", map[string]string{"VOTE_E1_V1_COPY": "V1"}, []string{voterHash("E1", "V1")}},
+		{"two voters duplicated", map[string]string{"VOTE_E1_V1_COPY": "V1", "VOTE_E1_V2_COPY": "V2", "VOTE_E1_V2_AGAIN": "V2"},
+			[]string{voterHash("E1", "V1"), voterHash("E1", "V2")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			env.createElection("E1_B", "C1")
+			env.setStatus("E1_B", "active")
+			env.mustVote("E1", "V1", "C1")
+			env.mustVote("E1", "V2", "C2")
+			// A vote in another election is not a duplicate
+			env.mustVote("E1_B", "V1", "C1")
+			for key, voterID := range tt.duplicates {
+				voteJSON, err := json.Marshal(Vote{ElectionID: "E1", VoterID: voterID, CandidateID: "C1"})
+				must(t, err)
+				must(t, env.stub.PutState(key, voteJSON))
+			}
+
+			got, err := env.contract.FindDuplicateVotes(env.ctx, "E1")
+			must(t, err)
+			want := append([]string{}, tt.want...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("duplicates = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateVotesRefused(t *testing.T) {
+	env := setupElection(t)
+	must(t, env.contract.RegisterObserver(env.ctx, "observer1", "ObserverMSP"))
+
+	env.asIdentity("observer1", "ObserverMSP", nil)
+	_, err := env.contract.FindDuplicateVotes(env.ctx, "E1")
+	must(t, err)
+	_, err = env.contract.FindDuplicateVotes(env.ctx, "MISSING")
+	mustFail(t, err, "does not exist")
+	env.asUser()
+	_, err = env.contract.FindDuplicateVotes(env.ctx, "E1")
+	mustFail(t, err, "neither an admin nor a registered observer")
+}
+
+func TestGetVoteTransactionIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		voters []string
+		legacy bool // Add a vote stored before transaction IDs were recorded
+	}{
+		{"no votes", nil, false},
+		{"votes in separate transactions", []string{"V3", "V1", "V2"}, false},
+		{"vote without a transaction ID", []string{"V1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			want := []string{}
+			for _, voterID := range tt.voters {
+				env.mustVote("E1", voterID, "C1")
+				want = append(want, env.stub.TxID)
+			}
+			if tt.legacy {
+				voteJSON, err := json.Marshal(Vote{ElectionID: "E1", VoterID: "V4", CandidateID: "C2"})
+				must(t, err)
+				must(t, env.stub.PutState("VOTE_E1_V4", voteJSON))
+			}
+			sort.Strings(want)
+
+			got, err := env.contract.GetVoteTransactionIDs(env.ctx, "E1")
+			must(t, err)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("transaction IDs = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetVoteTransactionIDsRefused(t *testing.T) {
+	env := setupElection(t)
+	must(t, env.contract.RegisterObserver(env.ctx, "observer1", "ObserverMSP"))
+
+	env.asIdentity("observer1", "ObserverMSP", nil)
+	_, err := env.contract.GetVoteTransactionIDs(env.ctx, "E1")
+	must(t, err)
+	_, err = env.contract.GetVoteTransactionIDs(env.ctx, "MISSING")
+	mustFail(t, err, "does not exist")
+	env.asUser()
+	_, err = env.contract.GetVoteTransactionIDs(env.ctx, "E1")
+	mustFail(t, err, "neither an admin nor a registered observer")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// GetLeaderboard returns the current counts of an election's candidates,
+// sorted by votes descending and then by serial number. Unlike
+// GetElectionResults it also works while the election is active, unless the
+// election hides its live results
+func (s *VotingContract) GetLeaderboard(ctx contractapi.TransactionContextInterface, electionID string) ([]CandidateResult, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkLiveResultsVisible(election)
+	if err != nil {
+		return nil, err
+	}
+	if election.Status == "ended" {
+		err = checkResultsReleasable(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.tallyElection(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	sortByVotes(result.CandidateResults)
+
+	return result.CandidateResults, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGetLeaderboard(t *testing.T) {
+	tests := []struct {
+		name    string
+		options func(env *testEnv) string
+		ended   bool
+		want    string
+	}{
+		{"live results", func(env *testEnv) string { return `{}` }, false, ""},
+		{"ended election", func(env *testEnv) string { return `{}` }, true, ""},
+		{"live results hidden", func(env *testEnv) string { return `{"hideLiveResults":true}` }, false, "hidden until it ends"},
+		{"hidden live results after the end", func(env *testEnv) string { return `{"hideLiveResults":true}` }, true, ""},
+		{"constituency still open", func(env *testEnv) string {
+			return fmt.Sprintf(`{"allConstituenciesMustClose":true,"constituencyEndTimes":{"North":%q}}`, env.now.Add(30*time.Minute).Format(time.RFC3339))
+		}, true, "results are withheld until constituency North closes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("E1", "C1", "C2", "C3")
+			env.configure("E1", tt.options(env))
+			env.setStatus("E1", "active")
+			env.mustVote("E1", "V1", "C3")
+			env.mustVote("E1", "V2", "C2")
+			env.mustVote("E1", "V3", "C3")
+			env.mustVote("E1", "V4", "C1")
+			if tt.ended {
+				env.endElection("E1")
+			}
+
+			leaderboard, err := env.contract.GetLeaderboard(env.ctx, "E1")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			// Ties are broken by serial number
+			want := []struct {
+				candidateID string
+				votes       int
+			}{{"C3", 2}, {"C1", 1}, {"C2", 1}}
+			if len(leaderboard) != len(want) {
+				t.Fatalf("leaderboard = %+v, want %d entries", leaderboard, len(want))
+			}
+			for i, entry := range leaderboard {
+				if entry.CandidateID != want[i].candidateID || entry.VoteCount != want[i].votes {
+					t.Errorf("entry %d = %s with %d votes, want %s with %d", i, entry.CandidateID, entry.VoteCount, want[i].candidateID, want[i].votes)
+				}
+			}
+		})
+	}
+
+	env := newTestEnv(t)
+	_, err := env.contract.GetLeaderboard(env.ctx, "MISSING")
+	mustFail(t, err, "does not exist")
+}
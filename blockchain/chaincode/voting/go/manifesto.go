@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxManifestoFieldLength caps the stored manifesto hash and URL
+const maxManifestoFieldLength = 2048
+
+// validateManifesto checks the manifesto hash and URL of a candidate. Both
+// may be empty; a URL must be absolute and use http or https
+func validateManifesto(manifestoHash string, manifestoURL string) error {
+	if len(manifestoHash) > maxManifestoFieldLength {
+		return fmt.Errorf("manifesto hash exceeds %d characters", maxManifestoFieldLength)
+	}
+	if manifestoURL == "" {
+		return nil
+	}
+	if len(manifestoURL) > maxManifestoFieldLength {
+		return fmt.Errorf("manifesto URL exceeds %d characters", maxManifestoFieldLength)
+	}
+	parsed, err := url.Parse(manifestoURL)
+	if err != nil {
+		return fmt.Errorf("invalid manifesto URL: %v", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid manifesto URL: %s. URL must be absolute and use http or https", manifestoURL)
+	}
+	return nil
+}
+
+// RegisterCandidateWithManifesto registers a candidate together with the
+// hash and URL of their manifesto
+func (s *VotingContract) RegisterCandidateWithManifesto(ctx contractapi.TransactionContextInterface, id string, name string, party string, constituency string, manifestoHash string, manifestoURL string) error {
+	err := validateManifesto(manifestoHash, manifestoURL)
+	if err != nil {
+		return err
+	}
+
+	candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + id)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if candidateJSON != nil {
+		return fmt.Errorf("the candidate %s already exists", id)
+	}
+
+	return s.putCandidate(ctx, &Candidate{
+		ID:            id,
+		Name:          name,
+		Party:         party,
+		Constituency:  constituency,
+		ManifestoHash: manifestoHash,
+		ManifestoURL:  manifestoURL,
+	})
+}
+
+// SetCandidateManifesto replaces the manifesto hash and URL of a registered candidate
+func (s *VotingContract) SetCandidateManifesto(ctx contractapi.TransactionContextInterface, id string, manifestoHash string, manifestoURL string) error {
+	err := validateManifesto(manifestoHash, manifestoURL)
+	if err != nil {
+		return err
+	}
+
+	candidate, err := s.GetCandidate(ctx, id)
+	if err != nil {
+		return err
+	}
+	candidate.ManifestoHash = manifestoHash
+	candidate.ManifestoURL = manifestoURL
+	return s.putCandidate(ctx, candidate)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateManifesto(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    string
+		url     string
+		wantErr string
+	}{
+		{"both empty", "", "", ""},
+		{"hash only", "sha256:abcd", "", ""},
+		{"https URL", "sha256:abcd", "https://example.org/manifesto.pdf", ""},
+		{"http URL", "", "http://example.org/m", ""},
+		{"relative URL", "", "/manifesto.pdf", "must be absolute"},
+		{"other scheme", "", "ftp://example.org/m", "must be absolute"},
+		{"hash too long", strings.Repeat("a", maxManifestoFieldLength+1), "", "manifesto hash exceeds"},
+		{"URL too long", "", "https://example.org/" + strings.Repeat("a", maxManifestoFieldLength), "manifesto URL exceeds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateManifesto(tt.hash, tt.url)
+			if tt.wantErr == "" {
+				must(t, err)
+				return
+			}
+			mustFail(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCandidateManifesto(t *testing.T) {
+	env := newTestEnv(t)
+	must(t, env.contract.RegisterCandidateWithManifesto(env.ctx, "C1", "Name C1", "Party C1", "North", "sha256:one", "https://example.org/one"))
+	err := env.contract.RegisterCandidateWithManifesto(env.ctx, "C1", "Name C1", "Party C1", "North", "", "")
+	mustFail(t, err, "already exists")
+	err = env.contract.RegisterCandidateWithManifesto(env.ctx, "C2", "Name C2", "Party C2", "North", "", "not a url")
+	mustFail(t, err, "invalid manifesto URL")
+
+	candidate, err := env.contract.GetCandidate(env.ctx, "C1")
+	must(t, err)
+	if candidate.ManifestoHash != "sha256:one" || candidate.ManifestoURL != "https://example.org/one" || candidate.Constituency != "North" {
+		t.Errorf("candidate = %+v", candidate)
+	}
+
+	must(t, env.contract.SetCandidateManifesto(env.ctx, "C1", "sha256:two", ""))
+	mustFail(t, env.contract.SetCandidateManifesto(env.ctx, "C1", "", "mailto:x@example.org"), "invalid manifesto URL")
+	mustFail(t, env.contract.SetCandidateManifesto(env.ctx, "C9", "", ""), "does not exist")
+
+	candidate, err = env.contract.GetCandidate(env.ctx, "C1")
+	must(t, err)
+	if candidate.ManifestoHash != "sha256:two" || candidate.ManifestoURL != "" || candidate.Name != "Name C1" {
+		t.Errorf("candidate after update = %+v", candidate)
+	}
+}
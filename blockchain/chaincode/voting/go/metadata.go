@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// setMetadataEntry validates and stores a single metadata entry on an election
+func setMetadataEntry(election *Election, key string, value string) error {
+	err := validateMetadataEntry(key, value)
+	if err != nil {
+		return err
+	}
+
+	if election.Metadata == nil {
+		election.Metadata = make(map[string]string)
+	}
+	if _, exists := election.Metadata[key]; !exists && len(election.Metadata) >= maxMetadataEntries {
+		return fmt.Errorf("election %s already has the maximum of %d metadata entries", election.ID, maxMetadataEntries)
+	}
+	election.Metadata[key] = value
+
+	return nil
+}
+
+// SetElectionMetadata sets a custom metadata entry on an election
+func (s *VotingContract) SetElectionMetadata(ctx contractapi.TransactionContextInterface, id string, key string, value string) error {
+	election, err := s.GetElection(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = setMetadataEntry(election, key, value)
+	if err != nil {
+		return err
+	}
+
+	return s.putElection(ctx, election)
+}
+
+// GetElectionMetadata returns the custom metadata of an election
+func (s *VotingContract) GetElectionMetadata(ctx contractapi.TransactionContextInterface, id string) (map[string]string, error) {
+	election, err := s.GetElection(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if election.Metadata == nil {
+		return map[string]string{}, nil
+	}
+
+	return election.Metadata, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSetElectionMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{"plain entry", "state", "Maharashtra", ""},
+		{"empty value", "note", "", ""},
+		{"empty key", "", "value", "must not be empty"},
+		{"key too long", strings.Repeat("k", maxMetadataKeyLength+1), "value", "exceeds 64 characters"},
+		{"value too long", "note", strings.Repeat("v", maxMetadataValueLength+1), "exceeds 1024 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.createElection("E1")
+
+			err := env.contract.SetElectionMetadata(env.ctx, "E1", tt.key, tt.value)
+			metadata, getErr := env.contract.GetElectionMetadata(env.ctx, "E1")
+			must(t, getErr)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				if len(metadata) != 0 {
+					t.Errorf("metadata = %v despite the refusal", metadata)
+				}
+				return
+			}
+			must(t, err)
+			if value, ok := metadata[tt.key]; !ok || value != tt.value {
+				t.Errorf("metadata = %v, want %s=%s", metadata, tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestElectionMetadataLimit(t *testing.T) {
+	env := newTestEnv(t)
+	env.createElection("E1")
+
+	metadata, err := env.contract.GetElectionMetadata(env.ctx, "E1")
+	must(t, err)
+	if metadata == nil || len(metadata) != 0 {
+		t.Fatalf("metadata = %v, want an empty map", metadata)
+	}
+
+	for i := 0; i < maxMetadataEntries; i++ {
+		must(t, env.contract.SetElectionMetadata(env.ctx, "E1", fmt.Sprintf("key%d", i), "value"))
+	}
+	mustFail(t, env.contract.SetElectionMetadata(env.ctx, "E1", "extra", "value"), "maximum of 32 metadata entries")
+	// Overwriting an existing key does not add an entry
+	must(t, env.contract.SetElectionMetadata(env.ctx, "E1", "key0", "updated"))
+
+	env.configure("E1", `{"metadata":{"key1":"merged"}}`)
+	metadata, err = env.contract.GetElectionMetadata(env.ctx, "E1")
+	must(t, err)
+	if metadata["key0"] != "updated" || metadata["key1"] != "merged" || len(metadata) != maxMetadataEntries {
+		t.Errorf("metadata has %d entries, key0=%s, key1=%s", len(metadata), metadata["key0"], metadata["key1"])
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Nomination statuses
+const (
+	NominationPending  = "pending"
+	NominationAccepted = "accepted"
+	NominationRejected = "rejected"
+)
+
+// Nomination records a candidate's nomination for an election. The candidate
+// only joins the ballot once the nomination is accepted
+type Nomination struct {
+	ElectionID  string    `json:"electionId"`
+	CandidateID string    `json:"candidateId"`
+	Status      string    `json:"status"`
+	NominatedAt time.Time `json:"nominatedAt"`
+	DecidedAt   time.Time `json:"decidedAt"` // Zero while pending
+}
+
+// nominationKey returns the world state key of a nomination
+func nominationKey(electionID string, candidateID string) string {
+	return "NOMINATION_" + electionID + "_" + candidateID
+}
+
+// getNomination returns a nomination, or nil when the candidate has not been nominated
+func (s *VotingContract) getNomination(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) (*Nomination, error) {
+	nominationJSON, err := ctx.GetStub().GetState(nominationKey(electionID, candidateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if nominationJSON == nil {
+		return nil, nil
+	}
+
+	var nomination Nomination
+	err = json.Unmarshal(nominationJSON, &nomination)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nomination, nil
+}
+
+// putNomination writes a nomination to the world state
+func (s *VotingContract) putNomination(ctx contractapi.TransactionContextInterface, nomination *Nomination) error {
+	nominationJSON, err := json.Marshal(nomination)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(nominationKey(nomination.ElectionID, nomination.CandidateID), nominationJSON)
+}
+
+// NominateCandidate nominates a registered candidate for an election that
+// has not started yet. The nomination stays pending until accepted or rejected
+func (s *VotingContract) NominateCandidate(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) error {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status != "created" {
+		return fmt.Errorf("candidates can only be nominated while election %s is in 'created' status", electionID)
+	}
+	if election.Type == ElectionTypeReferendum {
+		return fmt.Errorf("election %s is a referendum and takes no candidates", electionID)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = checkNominationDeadline(election, now)
+	if err != nil {
+		return err
+	}
+
+	if containsString(election.Candidates, candidateID) {
+		return fmt.Errorf("candidate %s is already part of election %s", candidateID, electionID)
+	}
+
+	_, err = s.GetCandidate(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.getNomination(ctx, electionID, candidateID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Status == NominationPending {
+		return fmt.Errorf("candidate %s already has a pending nomination for election %s", candidateID, electionID)
+	}
+
+	return s.putNomination(ctx, &Nomination{
+		ElectionID:  electionID,
+		CandidateID: candidateID,
+		Status:      NominationPending,
+		NominatedAt: now,
+	})
+}
+
+// checkNominationDeadline returns an error once the nomination deadline of
+// an election, if it has one, has passed
+func checkNominationDeadline(election *Election, now time.Time) error {
+	if !election.NominationDeadline.IsZero() && now.After(election.NominationDeadline) {
+		return fmt.Errorf("nominations for election %s closed at %s", election.ID, election.NominationDeadline.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// getPendingNomination returns the pending nomination of a candidate for an
+// election that has not started yet
+func (s *VotingContract) getPendingNomination(ctx contractapi.TransactionContextInterface, election *Election, candidateID string) (*Nomination, error) {
+	if election.Status != "created" {
+		return nil, fmt.Errorf("nominations can only be decided while election %s is in 'created' status", election.ID)
+	}
+
+	nomination, err := s.getNomination(ctx, election.ID, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if nomination == nil || nomination.Status != NominationPending {
+		return nil, fmt.Errorf("candidate %s has no pending nomination for election %s", candidateID, election.ID)
+	}
+
+	return nomination, nil
+}
+
+// AcceptNomination accepts a pending nomination, adding the candidate to the
+// election's ballot with the next serial number. Only the candidate may
+// accept, as identified by the candidateId attribute of their certificate,
+// and not after the nomination deadline or while the candidate list is sealed
+func (s *VotingContract) AcceptNomination(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) error {
+	err := requireCandidate(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+
+	nomination, err := s.getPendingNomination(ctx, election, candidateID)
+	if err != nil {
+		return err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = checkNominationDeadline(election, now)
+	if err != nil {
+		return err
+	}
+
+	// A sealed candidate list must not change until nominations are reopened
+	seal, err := s.getCandidateListSeal(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if seal != nil {
+		return fmt.Errorf("the candidate list of election %s is sealed", electionID)
+	}
+
+	config, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.ExclusiveCandidacy {
+		err = s.checkExclusiveCandidacy(ctx, []string{candidateID})
+		if err != nil {
+			return err
+		}
+	}
+	if config.MaxCandidatesPerConstituency > 0 {
+		err = s.checkCandidatesPerConstituency(ctx, append(append([]string{}, election.Candidates...), candidateID), config.MaxCandidatesPerConstituency)
+		if err != nil {
+			return err
+		}
+	}
+
+	nomination.Status = NominationAccepted
+	nomination.DecidedAt = now
+	err = s.putNomination(ctx, nomination)
+	if err != nil {
+		return err
+	}
+
+	serial := 0
+	for _, listedID := range election.Candidates {
+		if existing := candidateSerial(election, listedID); existing > serial {
+			serial = existing
+		}
+	}
+	if election.CandidateSerials == nil {
+		election.CandidateSerials = make(map[string]int)
+	}
+	election.Candidates = append(election.Candidates, candidateID)
+	election.CandidateSerials[candidateID] = serial + 1
+
+	return s.putElection(ctx, election)
+}
+
+// RejectNomination rejects a pending nomination. The candidate may be
+// nominated again later. Callable by the candidate or an admin
+func (s *VotingContract) RejectNomination(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) error {
+	if requireAdmin(ctx) != nil {
+		err := requireCandidate(ctx, candidateID)
+		if err != nil {
+			return err
+		}
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+
+	nomination, err := s.getPendingNomination(ctx, election, candidateID)
+	if err != nil {
+		return err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	nomination.Status = NominationRejected
+	nomination.DecidedAt = now
+
+	return s.putNomination(ctx, nomination)
+}
+
+// GetNomination returns the nomination of a candidate for an election
+func (s *VotingContract) GetNomination(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) (*Nomination, error) {
+	nomination, err := s.getNomination(ctx, electionID, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if nomination == nil {
+		return nil, fmt.Errorf("candidate %s has not been nominated for election %s", candidateID, electionID)
+	}
+
+	return nomination, nil
+}
+
+// parseNominationDeadline parses an RFC3339 nomination deadline, which must
+// not fall after the start of the election
+func parseNominationDeadline(election *Election, deadlineStr string) (time.Time, error) {
+	deadline, err := time.Parse(time.RFC3339, deadlineStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid nomination deadline format: %v", err)
+	}
+	if deadline.After(election.StartTime) {
+		return time.Time{}, fmt.Errorf("nomination deadline must not be after the election start time")
+	}
+	return deadline, nil
+}
+
+// ReopenNominations reopens nominations for an election that has not started
+// yet until a new deadline, which must lie in the future. Reopening removes
+// any seal on the candidate list, as accepted nominations will change it. Admin only
+func (s *VotingContract) ReopenNominations(ctx contractapi.TransactionContextInterface, electionID string, newDeadlineStr string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status != "created" {
+		return fmt.Errorf("nominations can only be reopened while election %s is in 'created' status", electionID)
+	}
+	if election.Type == ElectionTypeReferendum {
+		return fmt.Errorf("election %s is a referendum and takes no candidates", electionID)
+	}
+
+	deadline, err := parseNominationDeadline(election, newDeadlineStr)
+	if err != nil {
+		return err
+	}
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if !deadline.After(now) {
+		return fmt.Errorf("nomination deadline must be in the future")
+	}
+
+	seal, err := s.getCandidateListSeal(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if seal != nil {
+		err = ctx.GetStub().DelState("SEAL_" + electionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	election.NominationDeadline = deadline
+	return s.putElection(ctx, election)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// createFutureElection creates an election starting two hours after the
+// current time, so nominations can close before it starts
+func (e *testEnv) createFutureElection(id string, candidates ...string) {
+	e.t.Helper()
+	must(e.t, e.contract.CreateElection(e.ctx, id, "Election "+id, "", e.now.Add(2*time.Hour).Format(time.RFC3339), e.now.Add(4*time.Hour).Format(time.RFC3339), candidatesJSON(candidates)))
+}
+
+func TestNominationFlow(t *testing.T) {
+	tests := []struct {
+		name       string
+		decide     func(env *testEnv) error
+		want       string
+		wantStatus string
+		wantListed bool
+	}{
+		{"accepted by the candidate", func(env *testEnv) error {
+			env.asCandidate("C2")
+			return env.contract.AcceptNomination(env.ctx, "E1", "C2")
+		}, "", NominationAccepted, true},
+		{"accepted by an admin", func(env *testEnv) error {
+			return env.contract.AcceptNomination(env.ctx, "E1", "C2")
+		}, "access denied", NominationPending, false},
+		{"accepted by another candidate", func(env *testEnv) error {
+			env.asCandidate("C1")
+			return env.contract.AcceptNomination(env.ctx, "E1", "C2")
+		}, "access denied", NominationPending, false},
+		{"rejected by the candidate", func(env *testEnv) error {
+			env.asCandidate("C2")
+			return env.contract.RejectNomination(env.ctx, "E1", "C2")
+		}, "", NominationRejected, false},
+		{"rejected by an admin", func(env *testEnv) error {
+			return env.contract.RejectNomination(env.ctx, "E1", "C2")
+		}, "", NominationRejected, false},
+		{"rejected by an ordinary client", func(env *testEnv) error {
+			env.asUser()
+			return env.contract.RejectNomination(env.ctx, "E1", "C2")
+		}, "access denied", NominationPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			env.createElection("E1", "C1")
+			must(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"))
+			mustFail(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"), "already has a pending nomination")
+			if containsString(env.election("E1").Candidates, "C2") {
+				t.Fatalf("pending nominee is already on the ballot")
+			}
+
+			err := tt.decide(env)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+			} else {
+				must(t, err)
+			}
+
+			nomination, err := env.contract.GetNomination(env.ctx, "E1", "C2")
+			must(t, err)
+			if nomination.Status != tt.wantStatus {
+				t.Errorf("nomination status = %s, want %s", nomination.Status, tt.wantStatus)
+			}
+			if nomination.Status != NominationPending && nomination.DecidedAt.IsZero() {
+				t.Errorf("decided nomination has no decision time")
+			}
+
+			election := env.election("E1")
+			if listed := containsString(election.Candidates, "C2"); listed != tt.wantListed {
+				t.Errorf("C2 listed = %v, want %v", listed, tt.wantListed)
+			}
+			if tt.wantListed && election.CandidateSerials["C2"] != 2 {
+				t.Errorf("serial of C2 = %d, want 2", election.CandidateSerials["C2"])
+			}
+		})
+	}
+}
+
+func TestNominationDecidedOnce(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.createElection("E1", "C1")
+
+	mustFail(t, env.contract.NominateCandidate(env.ctx, "E1", "C1"), "already part of election")
+	mustFail(t, env.contract.NominateCandidate(env.ctx, "E1", "C9"), "does not exist")
+	_, err := env.contract.GetNomination(env.ctx, "E1", "C2")
+	mustFail(t, err, "has not been nominated")
+
+	// A rejected candidate may be nominated again, but a decision is final
+	must(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"))
+	must(t, env.contract.RejectNomination(env.ctx, "E1", "C2"))
+	env.asCandidate("C2")
+	mustFail(t, env.contract.AcceptNomination(env.ctx, "E1", "C2"), "no pending nomination")
+	env.asAdmin()
+	must(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"))
+	env.asCandidate("C2")
+	must(t, env.contract.AcceptNomination(env.ctx, "E1", "C2"))
+	mustFail(t, env.contract.RejectNomination(env.ctx, "E1", "C2"), "no pending nomination")
+
+	env.asAdmin()
+	env.setStatus("E1", "active")
+	mustFail(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"), "'created' status")
+}
+
+func TestNominationDeadline(t *testing.T) {
+	tests := []struct {
+		name  string
+		after time.Duration // Time of the action relative to the deadline
+		want  string
+	}{
+		{"before the deadline", -time.Minute, ""},
+		{"at the deadline", 0, ""},
+		{"after the deadline", time.Minute, "nominations for election E1 closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.createFutureElection("E1", "C1")
+			deadline := env.now.Add(time.Hour)
+			env.configure("E1", `{"nominationDeadline":"`+deadline.Format(time.RFC3339)+`"}`)
+			must(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"))
+
+			env.setTime(deadline.Add(tt.after))
+			env.asCandidate("C2")
+			err := env.contract.AcceptNomination(env.ctx, "E1", "C2")
+			env.asAdmin()
+			nominateErr := env.contract.NominateCandidate(env.ctx, "E1", "C3")
+			if tt.want == "" {
+				must(t, err)
+				must(t, nominateErr)
+				return
+			}
+			mustFail(t, err, tt.want)
+			mustFail(t, nominateErr, tt.want)
+			if containsString(env.election("E1").Candidates, "C2") {
+				t.Errorf("nomination accepted after the deadline")
+			}
+
+			// Reopening nominations lets the pending nomination be accepted
+			must(t, env.contract.ReopenNominations(env.ctx, "E1", env.now.Add(30*time.Minute).Format(time.RFC3339)))
+			env.asCandidate("C2")
+			must(t, env.contract.AcceptNomination(env.ctx, "E1", "C2"))
+		})
+	}
+}
+
+func TestReopenNominations(t *testing.T) {
+	tests := []struct {
+		name     string
+		prepare  func(env *testEnv)
+		deadline func(env *testEnv) string
+		want     string
+	}{
+		{"after the deadline passed", func(env *testEnv) {}, func(env *testEnv) string {
+			return env.now.Add(20 * time.Minute).Format(time.RFC3339)
+		}, ""},
+		{"sealed candidate list", func(env *testEnv) {
+			must(env.t, env.contract.SealElection(env.ctx, "E1"))
+		}, func(env *testEnv) string {
+			return env.now.Add(20 * time.Minute).Format(time.RFC3339)
+		}, ""},
+		{"active election", func(env *testEnv) {
+			env.setTime(env.election("E1").StartTime)
+			env.setStatus("E1", "active")
+		}, func(env *testEnv) string {
+			return env.now.Add(20 * time.Minute).Format(time.RFC3339)
+		}, "can only be reopened while election E1 is in 'created' status"},
+		{"non-admin", func(env *testEnv) { env.asUser() }, func(env *testEnv) string {
+			return env.now.Add(20 * time.Minute).Format(time.RFC3339)
+		}, "access denied"},
+		{"deadline in the past", func(env *testEnv) {}, func(env *testEnv) string {
+			return env.now.Add(-time.Minute).Format(time.RFC3339)
+		}, "must be in the future"},
+		{"deadline after the start", func(env *testEnv) {}, func(env *testEnv) string {
+			return env.election("E1").StartTime.Add(time.Minute).Format(time.RFC3339)
+		}, "must not be after the election start time"},
+		{"malformed deadline", func(env *testEnv) {}, func(env *testEnv) string { return "soon" }, "invalid nomination deadline format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			env.createFutureElection("E1", "C1")
+			env.configure("E1", `{"nominationDeadline":"`+env.now.Add(30*time.Minute).Format(time.RFC3339)+`"}`)
+			must(t, env.contract.NominateCandidate(env.ctx, "E1", "C2"))
+			env.advance(time.Hour)
+			tt.prepare(env)
+
+			deadline := tt.deadline(env)
+			err := env.contract.ReopenNominations(env.ctx, "E1", deadline)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+
+			if got := env.election("E1").NominationDeadline.Format(time.RFC3339); got != deadline {
+				t.Errorf("nomination deadline = %s, want %s", got, deadline)
+			}
+			seal, err := env.contract.getCandidateListSeal(env.ctx, "E1")
+			must(t, err)
+			if seal != nil {
+				t.Errorf("candidate list is still sealed after reopening")
+			}
+			env.asCandidate("C2")
+			must(t, env.contract.AcceptNomination(env.ctx, "E1", "C2"))
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ElectionOptions holds the optional settings applied by ConfigureElection.
+// Fields left out of the JSON keep their current value
+type ElectionOptions struct {
+	TestMode                    *bool             `json:"testMode,omitempty"`
+	AllowNOTA                   *bool             `json:"allowNota,omitempty"`
+	AllowBlank                  *bool             `json:"allowBlank,omitempty"`
+	AllowWriteIn                *bool             `json:"allowWriteIn,omitempty"`
+	AllowSpoiled                *bool             `json:"allowSpoiled,omitempty"`
+	AllowAbstain                *bool             `json:"allowAbstain,omitempty"`
+	RequireCheckIn              *bool             `json:"requireCheckIn,omitempty"`
+	SeatsPerConstituency        *int              `json:"seatsPerConstituency,omitempty"`
+	ConstituencyEndTimes        map[string]string `json:"constituencyEndTimes,omitempty"` // RFC3339 closing time per constituency
+	AllConstituenciesMustClose  *bool             `json:"allConstituenciesMustClose,omitempty"`
+	Constituencies              []string          `json:"constituencies,omitempty"`
+	Metadata                    map[string]string `json:"metadata,omitempty"` // Merged into the existing metadata
+	GracePeriodSeconds          *int              `json:"gracePeriodSeconds,omitempty"`
+	ExcludeLateVotes            *bool             `json:"excludeLateVotes,omitempty"`
+	WriteInMaxLength            *int              `json:"writeInMaxLength,omitempty"`
+	WriteInExtraChars           *string           `json:"writeInExtraChars,omitempty"`
+	ConstituencyPhases          map[string]int    `json:"constituencyPhases,omitempty"`
+	ShareAlertThresholds        []float64         `json:"shareAlertThresholds,omitempty"` // Percentages of valid votes
+	MaxTotalVotes               *int              `json:"maxTotalVotes,omitempty"`
+	ReceiptTemplate             *string           `json:"receiptTemplate,omitempty"`
+	RequirePriorParticipationIn *string           `json:"requirePriorParticipationIn,omitempty"` // Election ID, empty to lift the requirement
+	Type                        *string           `json:"type,omitempty"`                        // ElectionTypeReferendum, or empty for a candidate election
+	MinWinningMargin            *int              `json:"minWinningMargin,omitempty"`
+	QuorumPercent               *float64          `json:"quorumPercent,omitempty"`
+	MinCandidates               *int              `json:"minCandidates,omitempty"`
+	NominationDeadline          *string           `json:"nominationDeadline,omitempty"` // RFC3339, empty to remove the deadline
+	HideLiveResults             *bool             `json:"hideLiveResults,omitempty"`
+}
+
+// ConfigureElection applies optional settings to an election that has not started yet
+func (s *VotingContract) ConfigureElection(ctx contractapi.TransactionContextInterface, id string, optionsJSON string) error {
+	election, err := s.GetElection(ctx, id)
+	if err != nil {
+		return err
+	}
+	if election.Status != "created" {
+		return fmt.Errorf("election %s can only be configured while in 'created' status", id)
+	}
+
+	var options ElectionOptions
+	decoder := json.NewDecoder(bytes.NewReader([]byte(optionsJSON)))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&options)
+	if err != nil {
+		return fmt.Errorf("invalid election options JSON: %v", err)
+	}
+
+	err = applyElectionOptions(election, &options)
+	if err != nil {
+		return err
+	}
+	if options.RequirePriorParticipationIn != nil && election.RequirePriorParticipationIn != "" {
+		exists, err := s.ElectionExists(ctx, election.RequirePriorParticipationIn)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("prerequisite election %s does not exist", election.RequirePriorParticipationIn)
+		}
+	}
+
+	return s.putElection(ctx, election)
+}
+
+// applyElectionOptions validates the given options and copies the set ones onto the election
+func applyElectionOptions(election *Election, options *ElectionOptions) error {
+	if options.TestMode != nil {
+		election.TestMode = *options.TestMode
+	}
+	if options.AllowNOTA != nil {
+		election.AllowNOTA = *options.AllowNOTA
+	}
+	if options.AllowBlank != nil {
+		election.AllowBlank = *options.AllowBlank
+	}
+	if options.AllowWriteIn != nil {
+		election.AllowWriteIn = *options.AllowWriteIn
+	}
+	if options.AllowSpoiled != nil {
+		election.AllowSpoiled = *options.AllowSpoiled
+	}
+	if options.AllowAbstain != nil {
+		election.AllowAbstain = *options.AllowAbstain
+	}
+	if options.RequireCheckIn != nil {
+		election.RequireCheckIn = *options.RequireCheckIn
+	}
+	if options.SeatsPerConstituency != nil {
+		err := validatePositiveInt("seatsPerConstituency", *options.SeatsPerConstituency)
+		if err != nil {
+			return err
+		}
+		election.SeatsPerConstituency = *options.SeatsPerConstituency
+	}
+	if options.ConstituencyEndTimes != nil {
+		endTimes := make(map[string]time.Time)
+		for constituency, endTimeStr := range options.ConstituencyEndTimes {
+			endTime, err := time.Parse(time.RFC3339, endTimeStr)
+			if err != nil {
+				return fmt.Errorf("invalid end time format for constituency %s: %v", constituency, err)
+			}
+			if !endTime.After(election.StartTime) {
+				return fmt.Errorf("end time for constituency %s must be after the election start time", constituency)
+			}
+			endTimes[constituency] = endTime
+		}
+		election.ConstituencyEndTimes = endTimes
+	}
+	if options.AllConstituenciesMustClose != nil {
+		election.AllConstituenciesMustClose = *options.AllConstituenciesMustClose
+	}
+	if options.Constituencies != nil {
+		for _, constituency := range options.Constituencies {
+			if strings.TrimSpace(constituency) == "" {
+				return fmt.Errorf("constituency names must not be empty")
+			}
+		}
+		election.Constituencies = options.Constituencies
+	}
+	if options.GracePeriodSeconds != nil {
+		err := validateNonNegativeInt("gracePeriodSeconds", *options.GracePeriodSeconds)
+		if err != nil {
+			return err
+		}
+		election.GracePeriodSeconds = *options.GracePeriodSeconds
+	}
+	if options.ExcludeLateVotes != nil {
+		election.ExcludeLateVotes = *options.ExcludeLateVotes
+	}
+	if options.WriteInMaxLength != nil {
+		err := validatePositiveInt("writeInMaxLength", *options.WriteInMaxLength)
+		if err != nil {
+			return err
+		}
+		election.WriteInMaxLength = *options.WriteInMaxLength
+	}
+	if options.WriteInExtraChars != nil {
+		for _, r := range *options.WriteInExtraChars {
+			if unicode.IsControl(r) {
+				return fmt.Errorf("writeInExtraChars must not contain control characters")
+			}
+		}
+		election.WriteInExtraChars = *options.WriteInExtraChars
+	}
+	if options.ConstituencyPhases != nil {
+		for constituency, phase := range options.ConstituencyPhases {
+			if strings.TrimSpace(constituency) == "" {
+				return fmt.Errorf("constituency names must not be empty")
+			}
+			err := validatePositiveInt("phase for constituency "+constituency, phase)
+			if err != nil {
+				return err
+			}
+		}
+		election.ConstituencyPhases = options.ConstituencyPhases
+	}
+	if options.ShareAlertThresholds != nil {
+		for _, threshold := range options.ShareAlertThresholds {
+			err := validatePercentage("shareAlertThreshold", threshold)
+			if err != nil {
+				return err
+			}
+		}
+		election.ShareAlertThresholds = options.ShareAlertThresholds
+	}
+	if options.MaxTotalVotes != nil {
+		err := validateNonNegativeInt("maxTotalVotes", *options.MaxTotalVotes)
+		if err != nil {
+			return err
+		}
+		election.MaxTotalVotes = *options.MaxTotalVotes
+	}
+	if options.ReceiptTemplate != nil {
+		err := validateReceiptTemplate(*options.ReceiptTemplate)
+		if err != nil {
+			return err
+		}
+		election.ReceiptTemplate = *options.ReceiptTemplate
+	}
+	if options.RequirePriorParticipationIn != nil {
+		priorID := strings.TrimSpace(*options.RequirePriorParticipationIn)
+		if priorID == election.ID {
+			return fmt.Errorf("election %s cannot require participation in itself", election.ID)
+		}
+		election.RequirePriorParticipationIn = priorID
+	}
+	if options.Type != nil {
+		switch *options.Type {
+		case "":
+		case ElectionTypeReferendum:
+			if len(election.Candidates) > 0 {
+				return fmt.Errorf("referendum elections must not carry candidates")
+			}
+		default:
+			return fmt.Errorf("invalid election type %s", *options.Type)
+		}
+		election.Type = *options.Type
+	}
+	if options.MinWinningMargin != nil {
+		err := validateNonNegativeInt("minWinningMargin", *options.MinWinningMargin)
+		if err != nil {
+			return err
+		}
+		election.MinWinningMargin = *options.MinWinningMargin
+	}
+	if options.QuorumPercent != nil {
+		err := validatePercentage("quorumPercent", *options.QuorumPercent)
+		if err != nil {
+			return err
+		}
+		election.QuorumPercent = *options.QuorumPercent
+	}
+	if options.MinCandidates != nil {
+		err := validateNonNegativeInt("minCandidates", *options.MinCandidates)
+		if err != nil {
+			return err
+		}
+		election.MinCandidates = *options.MinCandidates
+	}
+	if options.NominationDeadline != nil {
+		deadline := time.Time{}
+		if *options.NominationDeadline != "" {
+			var err error
+			deadline, err = parseNominationDeadline(election, *options.NominationDeadline)
+			if err != nil {
+				return err
+			}
+		}
+		election.NominationDeadline = deadline
+	}
+	if options.HideLiveResults != nil {
+		election.HideLiveResults = *options.HideLiveResults
+	}
+	for key, value := range options.Metadata {
+		err := setMetadataEntry(election, key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// isPhaseOpen returns true when voters of a constituency may currently vote
+// under the election's polling phases. Unphased elections are always open
+func isPhaseOpen(election *Election, constituency string) bool {
+	if len(election.ConstituencyPhases) == 0 {
+		return true
+	}
+	phase, ok := election.ConstituencyPhases[constituency]
+	return ok && election.Phase != 0 && phase == election.Phase
+}
+
+// SetElectionPhase opens a polling phase of an election, closing the
+// previous one. Phase 0 closes polling in all phases. Admin only
+func (s *VotingContract) SetElectionPhase(ctx contractapi.TransactionContextInterface, electionID string, phase int) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status == "ended" {
+		return fmt.Errorf("election %s has already ended", electionID)
+	}
+	if len(election.ConstituencyPhases) == 0 {
+		return fmt.Errorf("election %s does not use polling phases", electionID)
+	}
+
+	if phase != 0 && !hasPhase(election, phase) {
+		return fmt.Errorf("election %s has no constituency in phase %d", electionID, phase)
+	}
+
+	election.Phase = phase
+	return s.putElection(ctx, election)
+}
+
+// GetActivePhases returns the polling phases currently accepting votes in an
+// election. It is empty while the election is not active or no phase is open
+func (s *VotingContract) GetActivePhases(ctx contractapi.TransactionContextInterface, electionID string) ([]int, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	phases := []int{}
+	if election.Status == "active" && election.Phase != 0 && len(election.ConstituencyPhases) > 0 {
+		phases = append(phases, election.Phase)
+	}
+
+	return phases, nil
+}
+
+// ActivationReport lists the outcome of ActivateElectionsByPhase
+type ActivationReport struct {
+	Phase         int      `json:"phase"`
+	Activated     []string `json:"activated"`
+	OutsideWindow []string `json:"outsideWindow"` // Created elections of the phase left alone as their voting window is not open
+}
+
+// ActivateElectionsByPhase activates every created election with a
+// constituency in the given polling phase whose voting window is open at the
+// transaction timestamp, and opens that phase in each. Admin only
+func (s *VotingContract) ActivateElectionsByPhase(ctx contractapi.TransactionContextInterface, phase int) (*ActivationReport, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validatePositiveInt("phase", phase)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := ActivationReport{
+		Phase:         phase,
+		Activated:     []string{},
+		OutsideWindow: []string{},
+	}
+	for _, election := range elections {
+		if election.Status != "created" || !hasPhase(election, phase) {
+			continue
+		}
+		if now.Before(election.StartTime) || now.After(votingDeadline(election)) {
+			report.OutsideWindow = append(report.OutsideWindow, election.ID)
+			continue
+		}
+		election.Status = "active"
+		election.Phase = phase
+		err = s.putElection(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		report.Activated = append(report.Activated, election.ID)
+	}
+
+	return &report, nil
+}
+
+// hasPhase returns true when a constituency of the election polls in the given phase
+func hasPhase(election *Election, phase int) bool {
+	for _, constituencyPhase := range election.ConstituencyPhases {
+		if constituencyPhase == phase {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestElectionPhases(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerCandidates("South", "C2")
+	env.registerVoters("North", "N1", "N2")
+	env.registerVoters("South", "S1")
+	env.registerVoters("East", "E1V")
+	env.createElection("E1", "C1", "C2")
+	env.configure("E1", `{"constituencies":["East"],"constituencyPhases":{"North":1,"South":2}}`)
+	env.setStatus("E1", "active")
+
+	phases, err := env.contract.GetActivePhases(env.ctx, "E1")
+	must(t, err)
+	if len(phases) != 0 {
+		t.Errorf("active phases = %v before any phase opened", phases)
+	}
+	mustFail(t, env.vote("E1", "N1", "C1"), "polling phase for constituency North is not open")
+
+	mustFail(t, env.contract.SetElectionPhase(env.ctx, "E1", 3), "no constituency in phase 3")
+	must(t, env.contract.SetElectionPhase(env.ctx, "E1", 1))
+	phases, err = env.contract.GetActivePhases(env.ctx, "E1")
+	must(t, err)
+	if fmt.Sprint(phases) != "[1]" {
+		t.Errorf("active phases = %v, want [1]", phases)
+	}
+
+	tests := []struct {
+		phase   int
+		voterID string
+		want    string
+	}{
+		{1, "N2", ""},
+		{1, "S1", "constituency South is not open"},
+		{1, "E1V", "constituency East is not open"}, // Not assigned to any phase
+		{2, "S1", ""},
+		{0, "N1", "constituency North is not open"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("phase %d voter %s", tt.phase, tt.voterID), func(t *testing.T) {
+			must(t, env.contract.SetElectionPhase(env.ctx, "E1", tt.phase))
+			env.advance(time.Minute)
+			err := env.vote("E1", tt.voterID, "C1")
+			if tt.want == "" {
+				must(t, err)
+				return
+			}
+			mustFail(t, err, tt.want)
+		})
+	}
+
+	env.asUser()
+	mustFail(t, env.contract.SetElectionPhase(env.ctx, "E1", 1), "access denied")
+	env.asAdmin()
+	env.endElection("E1")
+	mustFail(t, env.contract.SetElectionPhase(env.ctx, "E1", 1), "has already ended")
+
+	env.createElection("UNPHASED")
+	mustFail(t, env.contract.SetElectionPhase(env.ctx, "UNPHASED", 1), "does not use polling phases")
+}
+
+func TestActivateElectionsByPhase(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerCandidates("South", "C2")
+	env.registerVoters("North", "V1")
+
+	env.createElection("P1", "C1")
+	env.configure("P1", `{"constituencyPhases":{"North":1}}`)
+	env.createElection("P2", "C2")
+	env.configure("P2", `{"constituencyPhases":{"South":2}}`)
+	env.createFutureElection("FUTURE", "C1", "C2")
+	env.configure("FUTURE", `{"constituencyPhases":{"North":1,"South":2}}`)
+	env.createElection("RUNNING", "C1")
+	env.configure("RUNNING", `{"constituencyPhases":{"North":1}}`)
+	env.setStatus("RUNNING", "active")
+	env.createElection("UNPHASED", "C1")
+
+	tests := []struct {
+		phase             int
+		wantActivated     []string
+		wantOutsideWindow []string
+		wantStatus        map[string]string
+	}{
+		{1, []string{"P1"}, []string{"FUTURE"}, map[string]string{"P1": "active", "P2": "created", "FUTURE": "created", "UNPHASED": "created"}},
+		{2, []string{"P2"}, []string{"FUTURE"}, map[string]string{"P1": "active", "P2": "active", "FUTURE": "created", "UNPHASED": "created"}},
+		{1, []string{}, []string{"FUTURE"}, map[string]string{"P1": "active", "P2": "active", "FUTURE": "created"}},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("step %d phase %d", i+1, tt.phase), func(t *testing.T) {
+			report, err := env.contract.ActivateElectionsByPhase(env.ctx, tt.phase)
+			must(t, err)
+			if report.Phase != tt.phase || fmt.Sprint(report.Activated) != fmt.Sprint(tt.wantActivated) || fmt.Sprint(report.OutsideWindow) != fmt.Sprint(tt.wantOutsideWindow) {
+				t.Errorf("report = %+v, want activated %v and outside window %v", report, tt.wantActivated, tt.wantOutsideWindow)
+			}
+			for id, status := range tt.wantStatus {
+				if got := env.election(id).Status; got != status {
+					t.Errorf("status of %s = %s, want %s", id, got, status)
+				}
+			}
+		})
+	}
+
+	// Activation opens the phase, so voters of the phase can vote at once
+	if env.election("P1").Phase != 1 {
+		t.Errorf("phase of P1 = %d, want 1", env.election("P1").Phase)
+	}
+	env.mustVote("P1", "V1", "C1")
+
+	_, err := env.contract.ActivateElectionsByPhase(env.ctx, 0)
+	mustFail(t, err, "phase")
+	env.asUser()
+	_, err = env.contract.ActivateElectionsByPhase(env.ctx, 1)
+	mustFail(t, err, "access denied")
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// independentParty is the party under which candidates without one are grouped
+const independentParty = "Independent"
+
+// partyVotes sums the candidate votes of a result by party. Candidates
+// without a party, or without a candidate record, count as independents
+func (s *VotingContract) partyVotes(ctx contractapi.TransactionContextInterface, result *ElectionResult) (map[string]int, error) {
+	votes := make(map[string]int)
+	for _, candidateResult := range result.CandidateResults {
+		party := independentParty
+		candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateResult.CandidateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if candidateJSON != nil {
+			var candidate Candidate
+			err = json.Unmarshal(candidateJSON, &candidate)
+			if err != nil {
+				return nil, err
+			}
+			if candidate.Party != "" {
+				party = candidate.Party
+			}
+		}
+		votes[party] += candidateResult.VoteCount
+	}
+	return votes, nil
+}
+
+// hareAllocation allocates seats in proportion to votes by the largest
+// remainder method with the Hare quota. Each party first gets the whole
+// quotas it holds; the remaining seats go to the largest remainders, ties
+// going to the party with more votes and then to the lower party name
+func hareAllocation(votes map[string]int, totalSeats int) map[string]int {
+	totalVotes := 0
+	parties := make([]string, 0, len(votes))
+	for party, count := range votes {
+		totalVotes += count
+		parties = append(parties, party)
+	}
+
+	seats := make(map[string]int)
+	remainders := make(map[string]int) // Numerators over totalVotes, kept integral to avoid rounding
+	allocated := 0
+	for _, party := range parties {
+		seats[party] = votes[party] * totalSeats / totalVotes
+		remainders[party] = votes[party] * totalSeats % totalVotes
+		allocated += seats[party]
+	}
+
+	sort.Slice(parties, func(i, j int) bool {
+		if remainders[parties[i]] != remainders[parties[j]] {
+			return remainders[parties[i]] > remainders[parties[j]]
+		}
+		if votes[parties[i]] != votes[parties[j]] {
+			return votes[parties[i]] > votes[parties[j]]
+		}
+		return parties[i] < parties[j]
+	})
+	for i := 0; allocated < totalSeats; i++ {
+		seats[parties[i]]++
+		allocated++
+	}
+
+	return seats
+}
+
+// GetProportionalAllocation allocates totalSeats to parties in proportion to
+// their candidate votes in an ended election, using the largest remainder
+// (Hare) method. Every party that received votes is listed, even without a seat
+func (s *VotingContract) GetProportionalAllocation(ctx contractapi.TransactionContextInterface, electionID string, totalSeats int) (map[string]int, error) {
+	err := validatePositiveInt("totalSeats", totalSeats)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.allocatableVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return hareAllocation(votes, totalSeats), nil
+}
+
+// allocatableVotes returns the candidate votes of an ended election by party,
+// leaving out parties without votes. It fails when no candidate received a vote
+func (s *VotingContract) allocatableVotes(ctx contractapi.TransactionContextInterface, electionID string) (map[string]int, error) {
+	_, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.partyVotes(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	for party, count := range votes {
+		if count == 0 {
+			delete(votes, party)
+		}
+	}
+	if len(votes) == 0 {
+		return nil, fmt.Errorf("election %s has no candidate votes to allocate seats by", electionID)
+	}
+
+	return votes, nil
+}
+
+// gallagherIndex returns the least squares index of disproportionality
+// between vote and seat shares, sqrt(½·Σ(vᵢ−sᵢ)²) over percentages
+func gallagherIndex(votes map[string]int, seats map[string]int) float64 {
+	totalVotes := 0
+	for _, count := range votes {
+		totalVotes += count
+	}
+	totalSeats := 0
+	for _, count := range seats {
+		totalSeats += count
+	}
+
+	parties := make(map[string]bool)
+	for party := range votes {
+		parties[party] = true
+	}
+	for party := range seats {
+		parties[party] = true
+	}
+
+	sumOfSquares := 0.0
+	for party := range parties {
+		voteShare, seatShare := 0.0, 0.0
+		if totalVotes > 0 {
+			voteShare = float64(votes[party]) * 100 / float64(totalVotes)
+		}
+		if totalSeats > 0 {
+			seatShare = float64(seats[party]) * 100 / float64(totalSeats)
+		}
+		sumOfSquares += (voteShare - seatShare) * (voteShare - seatShare)
+	}
+
+	return math.Sqrt(sumOfSquares / 2)
+}
+
+// GetGallagherIndex returns the Gallagher least squares disproportionality
+// index of an ended election, rounded to 4 decimals. Seats are allocated
+// internally as GetProportionalAllocation does for the given totalSeats
+func (s *VotingContract) GetGallagherIndex(ctx contractapi.TransactionContextInterface, electionID string, totalSeats int) (float64, error) {
+	err := validatePositiveInt("totalSeats", totalSeats)
+	if err != nil {
+		return 0, err
+	}
+
+	votes, err := s.allocatableVotes(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+
+	index := gallagherIndex(votes, hareAllocation(votes, totalSeats))
+	return math.Round(index*10000) / 10000, nil
+}
+
+// GetEffectiveNumberOfParties returns the Laakso-Taagepera effective number
+// of parties of an ended election, 1/Σ(pᵢ²) over the parties' shares of
+// candidate votes, rounded to 4 decimals. It is 0 when no candidate received a vote
+func (s *VotingContract) GetEffectiveNumberOfParties(ctx contractapi.TransactionContextInterface, electionID string) (float64, error) {
+	_, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+
+	votes, err := s.partyVotes(ctx, result)
+	if err != nil {
+		return 0, err
+	}
+
+	totalVotes := 0
+	for _, count := range votes {
+		totalVotes += count
+	}
+	if totalVotes == 0 {
+		return 0, nil
+	}
+
+	sumOfSquares := 0.0
+	for _, count := range votes {
+		share := float64(count) / float64(totalVotes)
+		sumOfSquares += share * share
+	}
+
+	return math.Round(10000/sumOfSquares) / 10000, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHareAllocation(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes map[string]int
+		seats int
+		want  map[string]int
+	}{
+		{"largest remainders", map[string]int{"A": 47000, "B": 16000, "C": 15800, "D": 12000, "E": 6100, "F": 3100}, 10,
+			map[string]int{"A": 5, "B": 2, "C": 1, "D": 1, "E": 1, "F": 0}},
+		{"exact quotas", map[string]int{"A": 60, "B": 40}, 5, map[string]int{"A": 3, "B": 2}},
+		{"single party", map[string]int{"A": 7}, 3, map[string]int{"A": 3}},
+		{"equal remainders go to more votes", map[string]int{"A": 3, "B": 1}, 2, map[string]int{"A": 2, "B": 0}},
+		{"equal votes go to the lower name", map[string]int{"B": 1, "A": 1}, 1, map[string]int{"A": 1, "B": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hareAllocation(tt.votes, tt.seats)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("hareAllocation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// registerPartyCandidates registers candidates standing for a party in constituency North
+func (e *testEnv) registerPartyCandidates(party string, ids ...string) {
+	e.t.Helper()
+	for _, id := range ids {
+		must(e.t, e.contract.RegisterCandidate(e.ctx, id, "Name "+id, party, "North"))
+	}
+}
+
+func TestGetProportionalAllocation(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerPartyCandidates("Red", "R1", "R2")
+	env.registerPartyCandidates("Blue", "B1")
+	env.registerPartyCandidates("", "I1", "I2")
+	env.registerPartyCandidates("Green", "G1")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6", "V7", "V8", "V9", "V10")
+	env.createElection("E1", "R1", "R2", "B1", "I1", "I2", "G1")
+	env.setStatus("E1", "active")
+
+	// Red 5 votes across two candidates, Blue 3, independents 2, Green none
+	selections := []string{"R1", "R1", "R1", "R2", "R2", "B1", "B1", "B1", "I1", "I2"}
+	for i, candidateID := range selections {
+		env.mustVote("E1", fmt.Sprintf("V%d", i+1), candidateID)
+	}
+
+	_, err := env.contract.GetProportionalAllocation(env.ctx, "E1", 4)
+	var notEnded *ElectionNotEndedError
+	if !errors.As(err, &notEnded) {
+		t.Fatalf("allocation of an active election: got %v, want ElectionNotEndedError", err)
+	}
+	env.endElection("E1")
+
+	tests := []struct {
+		name    string
+		seats   int
+		want    map[string]int
+		wantErr string
+	}{
+		{"four seats", 4, map[string]int{"Red": 2, "Blue": 1, independentParty: 1}, ""},
+		{"ten seats", 10, map[string]int{"Red": 5, "Blue": 3, independentParty: 2}, ""},
+		{"one seat", 1, map[string]int{"Red": 1, "Blue": 0, independentParty: 0}, ""},
+		{"no seats", 0, nil, "totalSeats"},
+		{"negative seats", -2, nil, "totalSeats"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.contract.GetProportionalAllocation(env.ctx, "E1", tt.seats)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("allocation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetProportionalAllocationWithoutVotes(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerPartyCandidates("Red", "R1")
+	env.createElection("E1", "R1")
+	env.setStatus("E1", "active")
+	env.endElection("E1")
+
+	_, err := env.contract.GetProportionalAllocation(env.ctx, "E1", 3)
+	mustFail(t, err, "has no candidate votes")
+}
+
+func TestGetEffectiveNumberOfParties(t *testing.T) {
+	tests := []struct {
+		name       string
+		selections []string
+		want       float64
+	}{
+		{"no votes", nil, 0},
+		{"single party", []string{"R1", "R2", "R1"}, 1},
+		{"two equal parties", []string{"R1", "B1", "R2", "B1"}, 2},
+		// Shares 0.5, 0.25 and 0.25: 1 / (0.25 + 0.0625 + 0.0625)
+		{"uneven shares", []string{"R1", "R2", "B1", "I1"}, 2.6667},
+		// Independents count as one party: shares 0.5 and 0.5
+		{"independents grouped", []string{"I1", "I2", "R1", "R1"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerPartyCandidates("Red", "R1", "R2")
+			env.registerPartyCandidates("Blue", "B1")
+			env.registerPartyCandidates("", "I1", "I2")
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("E1", "R1", "R2", "B1", "I1", "I2")
+			env.setStatus("E1", "active")
+			for i, candidateID := range tt.selections {
+				env.mustVote("E1", fmt.Sprintf("V%d", i+1), candidateID)
+			}
+
+			_, err := env.contract.GetEffectiveNumberOfParties(env.ctx, "E1")
+			var notEnded *ElectionNotEndedError
+			if !errors.As(err, &notEnded) {
+				t.Fatalf("index of an active election: got %v, want ElectionNotEndedError", err)
+			}
+			env.endElection("E1")
+
+			got, err := env.contract.GetEffectiveNumberOfParties(env.ctx, "E1")
+			must(t, err)
+			if got != tt.want {
+				t.Errorf("effective number of parties = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGallagherIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes map[string]int
+		seats map[string]int
+		want  float64
+	}{
+		{"proportional", map[string]int{"A": 60, "B": 40}, map[string]int{"A": 3, "B": 2}, 0},
+		{"winner takes all", map[string]int{"A": 60, "B": 40}, map[string]int{"A": 1, "B": 0}, 40},
+		{"party without votes wins a seat", map[string]int{"A": 100}, map[string]int{"A": 1, "B": 1}, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gallagherIndex(tt.votes, tt.seats); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("gallagherIndex = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGallagherIndex(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerPartyCandidates("Red", "R1")
+	env.registerPartyCandidates("Blue", "B1")
+	env.registerPartyCandidates("", "I1")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6", "V7", "V8", "V9", "V10")
+	env.createElection("E1", "R1", "B1", "I1")
+	env.setStatus("E1", "active")
+
+	// Vote shares of 50%, 30% and 20%
+	selections := []string{"R1", "R1", "R1", "R1", "R1", "B1", "B1", "B1", "I1", "I1"}
+	for i, candidateID := range selections {
+		env.mustVote("E1", fmt.Sprintf("V%d", i+1), candidateID)
+	}
+	env.endElection("E1")
+
+	tests := []struct {
+		name    string
+		seats   int
+		want    float64
+		wantErr string
+	}{
+		{"exactly proportional", 10, 0, ""},
+		// Seat shares of 50%, 25% and 25%: sqrt((0² + 5² + 5²) / 2)
+		{"four seats", 4, 5, ""},
+		// Red takes the only seat: sqrt((50² + 30² + 20²) / 2)
+		{"one seat", 1, 43.589, ""},
+		{"no seats", 0, 0, "totalSeats"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.contract.GetGallagherIndex(env.ctx, "E1", tt.seats)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if got != tt.want {
+				t.Errorf("GetGallagherIndex(%d) = %v, want %v", tt.seats, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// GetCandidateActiveElections returns the active elections the candidate is listed in
+func (s *VotingContract) GetCandidateActiveElections(ctx contractapi.TransactionContextInterface, candidateID string) ([]*Election, error) {
+	_, err := s.GetCandidate(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+
+	elections, err := s.GetAllElections(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	active := []*Election{}
+	for _, election := range elections {
+		if election.Status != "active" {
+			continue
+		}
+		for _, cID := range election.Candidates {
+			if cID == candidateID {
+				active = append(active, election)
+				break
+			}
+		}
+	}
+
+	return active, nil
+}
+
+// GetUnassignedCandidates returns the candidates not listed in any election
+func (s *VotingContract) GetUnassignedCandidates(ctx contractapi.TransactionContextInterface) ([]*Candidate, error) {
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[string]bool)
+	for _, election := range elections {
+		for _, cID := range election.Candidates {
+			assigned[cID] = true
+		}
+	}
+
+	candidates, err := s.GetAllCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unassigned := []*Candidate{}
+	for _, candidate := range candidates {
+		if !assigned[candidate.ID] {
+			unassigned = append(unassigned, candidate)
+		}
+	}
+
+	return unassigned, nil
+}
+
+// CountElections returns the number of elections in world state, leaving out
+// test elections like GetAllElections does by default
+func (s *VotingContract) CountElections(ctx contractapi.TransactionContextInterface) (int, error) {
+	return countElections(ctx, "", false)
+}
+
+// CountElectionsIncludingTest returns the number of elections in world state,
+// test elections included
+func (s *VotingContract) CountElectionsIncludingTest(ctx contractapi.TransactionContextInterface) (int, error) {
+	return countElections(ctx, "", true)
+}
+
+// CountElectionsByStatus returns the number of elections with the given
+// status, leaving out test elections
+func (s *VotingContract) CountElectionsByStatus(ctx contractapi.TransactionContextInterface, status string) (int, error) {
+	status, err := normalizeElectionStatus(status)
+	if err != nil {
+		return 0, err
+	}
+
+	return countElections(ctx, status, false)
+}
+
+// CountElectionsByStatusIncludingTest returns the number of elections with
+// the given status, test elections included
+func (s *VotingContract) CountElectionsByStatusIncludingTest(ctx contractapi.TransactionContextInterface, status string) (int, error) {
+	status, err := normalizeElectionStatus(status)
+	if err != nil {
+		return 0, err
+	}
+
+	return countElections(ctx, status, true)
+}
+
+// countElections counts the elections with the given status, or with any
+// status when it is empty. Test elections are only counted when includeTest is set
+func countElections(ctx contractapi.TransactionContextInterface, status string, includeTest bool) (int, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	count := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+		if !isElectionKey(queryResponse.Key) {
+			continue
+		}
+
+		// Only the status and test flag are needed, so skip decoding the rest of the election
+		var summary struct {
+			Status   string `json:"status"`
+			TestMode bool   `json:"testMode"`
+		}
+		err = json.Unmarshal(queryResponse.Value, &summary)
+		if err != nil {
+			continue
+		}
+		if summary.TestMode && !includeTest {
+			continue
+		}
+		if status == "" || summary.Status == status {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// checkExclusiveCandidacy returns an error when any of the candidates is
+// already listed in another created or active election
+func (s *VotingContract) checkExclusiveCandidacy(ctx contractapi.TransactionContextInterface, candidates []string) error {
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	for _, election := range elections {
+		if election.Status == "ended" {
+			continue
+		}
+		for _, candidateID := range candidates {
+			if containsString(election.Candidates, candidateID) {
+				return fmt.Errorf("candidate %s is already standing in election %s", candidateID, election.ID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetElectionsModifiedSince returns the elections written after the given
+// RFC3339 time, for incremental sync by external mirrors
+func (s *VotingContract) GetElectionsModifiedSince(ctx contractapi.TransactionContextInterface, sinceStr string) ([]*Election, error) {
+	since, err := time.Parse(time.RFC3339, sinceStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since time format: %v", err)
+	}
+
+	elections, err := s.GetAllElections(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := []*Election{}
+	for _, election := range elections {
+		if election.LastModified.After(since) {
+			modified = append(modified, election)
+		}
+	}
+
+	return modified, nil
+}
+
+// GetVotesSince returns the votes of an election cast after the given RFC3339
+// time, oldest first, for incremental tallying. The voter ID and transaction
+// ID of each vote are left out. Refused while the election hides its live
+// results. Admin or observer only
+func (s *VotingContract) GetVotesSince(ctx contractapi.TransactionContextInterface, electionID string, sinceTimestamp string) ([]*Vote, error) {
+	err := requireAdminOrObserver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	since, err := time.Parse(time.RFC3339, sinceTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since time format: %v", err)
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkLiveResultsVisible(election)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	newVotes := []*Vote{}
+	for _, vote := range votes {
+		if !vote.Timestamp.After(since) {
+			continue
+		}
+		newVotes = append(newVotes, &Vote{
+			ElectionID:  vote.ElectionID,
+			CandidateID: vote.CandidateID,
+			Timestamp:   vote.Timestamp,
+			Late:        vote.Late,
+		})
+	}
+	sort.SliceStable(newVotes, func(i, j int) bool {
+		return newVotes[i].Timestamp.Before(newVotes[j].Timestamp)
+	})
+
+	return newVotes, nil
+}
+
+// GetVotersByIDs returns the voters found among a JSON array of voter IDs,
+// keyed by ID. IDs without a voter record are left out of the map
+func (s *VotingContract) GetVotersByIDs(ctx contractapi.TransactionContextInterface, idsJSON string) (map[string]*Voter, error) {
+	var ids []string
+	err := json.Unmarshal([]byte(idsJSON), &ids)
+	if err != nil {
+		return nil, fmt.Errorf("invalid voter IDs JSON: %v", err)
+	}
+
+	voters := make(map[string]*Voter)
+	for _, id := range ids {
+		if _, ok := voters[id]; ok {
+			continue
+		}
+		voterJSON, err := ctx.GetStub().GetState("VOTER_" + id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if voterJSON == nil {
+			continue
+		}
+
+		var voter Voter
+		err = json.Unmarshal(voterJSON, &voter)
+		if err != nil {
+			return nil, err
+		}
+		voters[id] = &voter
+	}
+
+	return voters, nil
+}
+
+// GetLateVoters counts the votes of an election cast within the final
+// windowMinutes before its EndTime. Votes accepted during a grace period
+// after EndTime are not counted
+func (s *VotingContract) GetLateVoters(ctx contractapi.TransactionContextInterface, electionID string, windowMinutes int) (int, error) {
+	err := validatePositiveInt("windowMinutes", windowMinutes)
+	if err != nil {
+		return 0, err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+
+	windowStart := election.EndTime.Add(-time.Duration(windowMinutes) * time.Minute)
+	count := 0
+	for _, vote := range votes {
+		if !vote.Timestamp.Before(windowStart) && !vote.Timestamp.After(election.EndTime) {
+			count++
+		}
+	}
+
+	return count, nil
+}
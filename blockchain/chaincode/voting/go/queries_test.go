@@ -0,0 +1,415 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetCandidateActiveElections(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.createElection("ACTIVE", "C1", "C2")
+	env.setStatus("ACTIVE", "active")
+	env.createElection("PENDING", "C1")
+	env.createElection("CLOSED", "C1")
+	env.setStatus("CLOSED", "active")
+	env.setStatus("CLOSED", "ended")
+	env.createElection("OTHER", "C2")
+	env.setStatus("OTHER", "active")
+
+	tests := []struct {
+		name        string
+		candidateID string
+		want        []string
+		wantErr     string
+	}{
+		{"listed in active, created and ended elections", "C1", []string{"ACTIVE"}, ""},
+		{"listed in two active elections", "C2", []string{"ACTIVE", "OTHER"}, ""},
+		{"unregistered candidate", "C9", nil, "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elections, err := env.contract.GetCandidateActiveElections(env.ctx, tt.candidateID)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if len(elections) != len(tt.want) {
+				t.Fatalf("got %d elections, want %v", len(elections), tt.want)
+			}
+			for i, election := range elections {
+				if election.ID != tt.want[i] {
+					t.Errorf("election %d = %s, want %s", i, election.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetUnassignedCandidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		elections map[string][]string
+		want      []string
+	}{
+		{"no elections", nil, []string{"C1", "C2", "C3"}},
+		{"some candidates listed", map[string][]string{"E1": {"C1"}, "E2": {"C3"}}, []string{"C2"}},
+		{"every candidate listed", map[string][]string{"E1": {"C1", "C2", "C3"}}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			for electionID, candidates := range tt.elections {
+				env.createElection(electionID, candidates...)
+			}
+
+			candidates, err := env.contract.GetUnassignedCandidates(env.ctx)
+			must(t, err)
+			if candidates == nil {
+				t.Fatalf("got nil, want an empty list at least")
+			}
+			if len(candidates) != len(tt.want) {
+				t.Fatalf("got %d candidates, want %v", len(candidates), tt.want)
+			}
+			for i, candidate := range candidates {
+				if candidate.ID != tt.want[i] {
+					t.Errorf("candidate %d = %s, want %s", i, candidate.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCountElections(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerVoters("North", "V1")
+	env.createElection("CREATED1")
+	env.createElection("CREATED2")
+	env.createElection("ACTIVE1", "C1")
+	env.setStatus("ACTIVE1", "active")
+	env.createElection("ENDED1")
+	env.setStatus("ENDED1", "ended")
+	env.createElection("TEST1")
+	env.configure("TEST1", `{"testMode":true}`)
+	env.setStatus("TEST1", "active")
+	// Records of other kinds must not be counted
+	env.mustVote("ACTIVE1", "V1", "C1")
+
+	count, err := env.contract.CountElections(env.ctx)
+	must(t, err)
+	if count != 4 {
+		t.Errorf("CountElections = %d, want 4", count)
+	}
+	count, err = env.contract.CountElectionsIncludingTest(env.ctx)
+	must(t, err)
+	if count != 5 {
+		t.Errorf("CountElectionsIncludingTest = %d, want 5", count)
+	}
+
+	tests := []struct {
+		status          string
+		want            int
+		wantIncludeTest int
+	}{
+		{"created", 2, 2},
+		{"active", 1, 2},
+		{" Ended ", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			count, err := env.contract.CountElectionsByStatus(env.ctx, tt.status)
+			must(t, err)
+			if count != tt.want {
+				t.Errorf("CountElectionsByStatus(%q) = %d, want %d", tt.status, count, tt.want)
+			}
+			count, err = env.contract.CountElectionsByStatusIncludingTest(env.ctx, tt.status)
+			must(t, err)
+			if count != tt.wantIncludeTest {
+				t.Errorf("CountElectionsByStatusIncludingTest(%q) = %d, want %d", tt.status, count, tt.wantIncludeTest)
+			}
+		})
+	}
+
+	_, err = env.contract.CountElectionsByStatus(env.ctx, "paused")
+	mustFail(t, err, "invalid status")
+}
+
+func TestExclusiveCandidacy(t *testing.T) {
+	tests := []struct {
+		name      string
+		exclusive bool
+		other     string // Status of the election C1 already stands in
+		shared    bool
+		want      string
+	}{
+		{"not enforced", false, "active", false, ""},
+		{"candidate in a created election", true, "created", false, "candidate C1 is already standing in election OTHER"},
+		{"candidate in an active election", true, "active", false, "candidate C1 is already standing in election OTHER"},
+		{"candidate's election has ended", true, "ended", false, ""},
+		{"shared candidates allowed", true, "active", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			env.createElection("OTHER", "C1")
+			if tt.other != "created" {
+				env.setStatus("OTHER", tt.other)
+			}
+			if tt.exclusive {
+				env.setConfig(`{"exclusiveCandidacy":true}`)
+			}
+
+			start := testNow.Format(time.RFC3339)
+			end := testNow.Add(time.Hour).Format(time.RFC3339)
+			create := env.contract.CreateElection
+			if tt.shared {
+				create = env.contract.CreateElectionSharedCandidates
+			}
+			err := create(env.ctx, "E1", "Election", "", start, end, `["C2","C1"]`)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				exists, err := env.contract.ElectionExists(env.ctx, "E1")
+				must(t, err)
+				if exists {
+					t.Errorf("election was created despite the refusal")
+				}
+				return
+			}
+			must(t, err)
+		})
+	}
+
+	env := newTestEnv(t)
+	env.asUser()
+	err := env.contract.CreateElectionSharedCandidates(env.ctx, "E1", "Election", "", testNow.Format(time.RFC3339), testNow.Add(time.Hour).Format(time.RFC3339), "[]")
+	mustFail(t, err, "access denied")
+}
+
+func TestGetElectionsModifiedSince(t *testing.T) {
+	env := newTestEnv(t)
+	env.createElection("E1")
+	env.createElection("E2")
+	env.advance(time.Hour)
+	env.setStatus("E2", "active")
+	env.createElection("E3")
+	env.advance(time.Hour)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  []string
+	}{
+		{"before every write", testNow.Add(-time.Minute), []string{"E1", "E2", "E3"}},
+		{"at the first writes", testNow, []string{"E2", "E3"}},
+		{"between the writes", testNow.Add(30 * time.Minute), []string{"E2", "E3"}},
+		{"after every write", testNow.Add(time.Hour), []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elections, err := env.contract.GetElectionsModifiedSince(env.ctx, tt.since.Format(time.RFC3339))
+			must(t, err)
+			ids := []string{}
+			for _, election := range elections {
+				ids = append(ids, election.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("modified elections = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	_, err := env.contract.GetElectionsModifiedSince(env.ctx, "yesterday")
+	mustFail(t, err, "invalid since time format")
+}
+
+func TestGetVotersByIDs(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerVoters("North", "V1", "V2")
+	env.registerVoters("South", "V3")
+
+	tests := []struct {
+		name    string
+		ids     string
+		want    []string
+		wantErr string
+	}{
+		{"all found", `["V1","V3"]`, []string{"V1", "V3"}, ""},
+		{"missing IDs left out", `["V2","V9"]`, []string{"V2"}, ""},
+		{"duplicates", `["V1","V1"]`, []string{"V1"}, ""},
+		{"empty list", `[]`, []string{}, ""},
+		{"not JSON", `V1,V2`, nil, "invalid voter IDs JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voters, err := env.contract.GetVotersByIDs(env.ctx, tt.ids)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if len(voters) != len(tt.want) {
+				t.Errorf("got %d voters, want %v", len(voters), tt.want)
+			}
+			for _, id := range tt.want {
+				if voter, ok := voters[id]; !ok || voter.ID != id || voter.Name != "Voter "+id {
+					t.Errorf("voter %s = %+v", id, voter)
+				}
+			}
+		})
+	}
+}
+
+func TestGetLateVoters(t *testing.T) {
+	tests := []struct {
+		name          string
+		windowMinutes int
+		want          int
+		wantErr       string
+	}{
+		{"zero window", 0, 0, "windowMinutes"},
+		{"negative window", -5, 0, "windowMinutes"},
+		{"final ten minutes", 10, 2, ""},
+		{"final thirty minutes", 30, 3, ""},
+		{"whole election", 120, 4, ""},
+	}
+
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5")
+	env.createElection("E1", "C1")
+	env.configure("E1", `{"gracePeriodSeconds":60}`)
+	env.setStatus("E1", "active")
+	endTime := env.election("E1").EndTime
+
+	// V1 votes early, V2 within the final thirty minutes, V3 and V4 within
+	// the final ten and V5 during the grace period after the end
+	offsets := map[string]time.Duration{
+		"V1": -90 * time.Minute,
+		"V2": -20 * time.Minute,
+		"V3": -5 * time.Minute,
+		"V4": 0,
+		"V5": 30 * time.Second,
+	}
+	for _, voterID := range []string{"V1", "V2", "V3", "V4", "V5"} {
+		env.setTime(endTime.Add(offsets[voterID]))
+		env.mustVote("E1", voterID, "C1")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.contract.GetLateVoters(env.ctx, "E1", tt.windowMinutes)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if got != tt.want {
+				t.Errorf("GetLateVoters(%d) = %d, want %d", tt.windowMinutes, got, tt.want)
+			}
+		})
+	}
+
+	_, err := env.contract.GetLateVoters(env.ctx, "MISSING", 10)
+	mustFail(t, err, "does not exist")
+}
+
+func TestGetVotesSince(t *testing.T) {
+	tests := []struct {
+		name    string
+		since   string
+		want    []string // Candidates of the returned votes, oldest first
+		wantErr string
+	}{
+		{"cutoff before every vote", testNow.Format(time.RFC3339), []string{"C2", "C1", "C1"}, ""},
+		{"cutoff between votes", testNow.Add(90 * time.Second).Format(time.RFC3339), []string{"C1", "C1"}, ""},
+		{"cutoff at a vote", testNow.Add(2 * time.Minute).Format(time.RFC3339), []string{"C1"}, ""},
+		{"cutoff after every vote", testNow.Add(time.Hour).Format(time.RFC3339), []string{}, ""},
+		{"malformed cutoff", "yesterday", nil, "invalid since time format"},
+	}
+
+	// V3 votes first, so ledger key order differs from time order
+	env := setupElection(t)
+	env.advance(time.Minute)
+	env.mustVote("E1", "V3", "C2")
+	env.advance(time.Minute)
+	env.mustVote("E1", "V1", "C1")
+	env.advance(time.Minute)
+	env.mustVote("E1", "V2", "C1")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			votes, err := env.contract.GetVotesSince(env.ctx, "E1", tt.since)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if len(votes) != len(tt.want) {
+				t.Fatalf("got %d votes, want %d", len(votes), len(tt.want))
+			}
+			for i, vote := range votes {
+				if vote.CandidateID != tt.want[i] {
+					t.Errorf("vote %d is for %s, want %s", i, vote.CandidateID, tt.want[i])
+				}
+				if vote.VoterID != "" || vote.TxID != "" {
+					t.Errorf("vote %d is not anonymized: %+v", i, vote)
+				}
+				if i > 0 && vote.Timestamp.Before(votes[i-1].Timestamp) {
+					t.Errorf("vote %d is older than the one before it", i)
+				}
+			}
+		})
+	}
+}
+
+func TestGetVotesSinceRefused(t *testing.T) {
+	since := testNow.Add(-time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name    string
+		options string
+		as      func(env *testEnv)
+		ended   bool
+		want    string
+	}{
+		{"admin", `{}`, func(env *testEnv) {}, false, ""},
+		{"registered observer", `{}`, func(env *testEnv) { env.asIdentity("observer1", "ObserverMSP", nil) }, false, ""},
+		{"ordinary client", `{}`, func(env *testEnv) { env.asUser() }, false, "neither an admin nor a registered observer"},
+		{"live results hidden", `{"hideLiveResults":true}`, func(env *testEnv) {}, false, "hidden until it ends"},
+		{"hidden live results after the end", `{"hideLiveResults":true}`, func(env *testEnv) {}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1")
+			must(t, env.contract.RegisterObserver(env.ctx, "observer1", "ObserverMSP"))
+			env.createElection("E1", "C1")
+			env.configure("E1", tt.options)
+			env.setStatus("E1", "active")
+			env.mustVote("E1", "V1", "C1")
+			if tt.ended {
+				env.endElection("E1")
+			}
+			tt.as(env)
+
+			votes, err := env.contract.GetVotesSince(env.ctx, "E1", since)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			if len(votes) != 1 {
+				t.Errorf("got %d votes, want 1", len(votes))
+			}
+		})
+	}
+}
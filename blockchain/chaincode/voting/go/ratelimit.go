@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// VoteAttempt records when a voter last attempted to vote in an election
+type VoteAttempt struct {
+	ElectionID    string    `json:"electionId"`
+	VoterID       string    `json:"voterId"`
+	LastAttemptAt time.Time `json:"lastAttemptAt"`
+}
+
+// inVoteCooldown returns true, along with the configured cooldown, when the
+// voter's previous attempt to vote in the election was made within the cooldown
+func (s *VotingContract) inVoteCooldown(ctx contractapi.TransactionContextInterface, electionID string, voterID string, now time.Time) (bool, int, error) {
+	config, err := getConfig(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	if config.VoteCooldownSeconds == 0 {
+		return false, 0, nil
+	}
+
+	attemptJSON, err := ctx.GetStub().GetState("ATTEMPT_" + electionID + "_" + voterID)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if attemptJSON == nil {
+		return false, config.VoteCooldownSeconds, nil
+	}
+
+	var attempt VoteAttempt
+	err = json.Unmarshal(attemptJSON, &attempt)
+	if err != nil {
+		return false, 0, err
+	}
+
+	cooldown := time.Duration(config.VoteCooldownSeconds) * time.Second
+	return now.Before(attempt.LastAttemptAt.Add(cooldown)), config.VoteCooldownSeconds, nil
+}
+
+// recordVoteAttempt stores the time of the voter's latest attempt to vote in
+// an election. A transaction that returns an error leaves no writes behind,
+// so refused attempts must be reported through a VoteOutcome to be kept
+func (s *VotingContract) recordVoteAttempt(ctx contractapi.TransactionContextInterface, electionID string, voterID string, now time.Time) error {
+	attempt := VoteAttempt{
+		ElectionID:    electionID,
+		VoterID:       voterID,
+		LastAttemptAt: now,
+	}
+	attemptJSON, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("ATTEMPT_"+electionID+"_"+voterID, attemptJSON)
+}
+
+// VoteRefusedError reports that a vote attempt by an existing voter was
+// refused after the attempt was recorded
+type VoteRefusedError struct {
+	Reason string
+}
+
+func (e *VoteRefusedError) Error() string {
+	return e.Reason
+}
+
+// VoteOutcome reports whether a vote was recorded. Refused attempts are
+// reported here rather than as errors, so that the transaction commits the
+// attempt and the voter's cooldown starts
+type VoteOutcome struct {
+	Accepted    bool   `json:"accepted"`
+	Reason      string `json:"reason,omitempty"`      // Why the vote was refused
+	ReceiptHash string `json:"receiptHash,omitempty"` // Set by CastVoteWithReceipt
+}
+
+// voteOutcome turns the error of castVote into the outcome returned to
+// clients, keeping errors for failures that record nothing
+func voteOutcome(err error) (*VoteOutcome, error) {
+	var refused *VoteRefusedError
+	if errors.As(err, &refused) {
+		return &VoteOutcome{Reason: refused.Reason}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &VoteOutcome{Accepted: true}, nil
+}
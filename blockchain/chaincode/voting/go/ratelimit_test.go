@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVoteCooldown(t *testing.T) {
+	type attempt struct {
+		after     time.Duration // Since the previous attempt
+		voterID   string
+		candidate string
+		want      string
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		attempts []attempt
+	}{
+		{
+			name: "retry after a rejected attempt",
+			attempts: []attempt{
+				{0, "V1", "C9", "not part of this election"},
+				{5 * time.Second, "V1", "C1", "too many attempts"},
+				{30 * time.Second, "V1", "C1", ""},
+			},
+		},
+		{
+			name: "rapid retries keep restarting the cooldown",
+			attempts: []attempt{
+				{0, "V1", "C9", "not part of this election"},
+				{20 * time.Second, "V1", "C1", "too many attempts"},
+				{20 * time.Second, "V1", "C1", "too many attempts"},
+				{20 * time.Second, "V1", "C1", "too many attempts"},
+				{30 * time.Second, "V1", "C1", ""},
+			},
+		},
+		{
+			name: "already voted stays distinct from the cooldown",
+			attempts: []attempt{
+				{0, "V1", "C1", ""},
+				{time.Second, "V1", "C2", "voter has already cast a vote"},
+				{time.Minute, "V1", "C2", "voter has already cast a vote"},
+			},
+		},
+		{
+			name: "cooldown is per voter",
+			attempts: []attempt{
+				{0, "V1", "C9", "not part of this election"},
+				{time.Second, "V2", "C1", ""},
+				{time.Second, "V1", "C1", "too many attempts"},
+			},
+		},
+		{
+			name:   "cooldown disabled",
+			config: `{"voteCooldownSeconds":0}`,
+			attempts: []attempt{
+				{0, "V1", "C9", "not part of this election"},
+				{0, "V1", "C1", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			if tt.config != "" {
+				env.setConfig(tt.config)
+			}
+			for i, a := range tt.attempts {
+				env.advance(a.after)
+				err := env.vote("E1", a.voterID, a.candidate)
+				if a.want == "" {
+					if err != nil {
+						t.Fatalf("attempt %d: unexpected error: %v", i, err)
+					}
+					continue
+				}
+				mustFail(t, err, a.want)
+			}
+		})
+	}
+}
+
+func TestVoterEligibilityReportsCooldown(t *testing.T) {
+	env := setupElection(t)
+	mustFail(t, env.vote("E1", "V1", "C9"), "not part of this election")
+
+	report, err := env.contract.GetVoterEligibility(env.ctx, "E1", "V1")
+	must(t, err)
+	if report.Eligible || !report.CoolingDown {
+		t.Errorf("report = %+v, want the voter cooling down", report)
+	}
+
+	env.advance(30 * time.Second)
+	report, err = env.contract.GetVoterEligibility(env.ctx, "E1", "V1")
+	must(t, err)
+	if !report.Eligible || report.CoolingDown {
+		t.Errorf("report = %+v, want the voter eligible after the cooldown", report)
+	}
+}
+
+func TestRefusedVoteOutcome(t *testing.T) {
+	tests := []struct {
+		name        string
+		electionID  string
+		voterID     string
+		candidateID string
+		wantReason  string // Reason of a refusal that commits the attempt
+		wantErr     string // Error of a failure that commits nothing
+	}{
+		{"accepted", "E1", "V1", "C1", "", ""},
+		{"invalid selection", "E1", "V1", "C9", "candidate is not part of this election", ""},
+		{"voter outside the election", "E1", "V5", "C1", "which election E1 does not cover", ""},
+		{"unknown voter", "E1", "V9", "C1", "", "the voter V9 does not exist"},
+		{"unknown election", "MISSING", "V1", "C1", "", "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			env.registerVoters("South", "V5")
+
+			var outcome *VoteOutcome
+			err := env.submit(func() (err error) {
+				outcome, err = env.contract.CastVote(env.ctx, tt.electionID, tt.voterID, tt.candidateID)
+				return err
+			})
+			attemptJSON, _ := env.stub.GetState("ATTEMPT_" + tt.electionID + "_" + tt.voterID)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				if attemptJSON != nil {
+					t.Errorf("attempt recorded by a failed transaction")
+				}
+				return
+			}
+			must(t, err)
+			if attemptJSON == nil {
+				t.Errorf("attempt not recorded")
+			}
+			if outcome.Accepted != (tt.wantReason == "") || !strings.Contains(outcome.Reason, tt.wantReason) {
+				t.Errorf("outcome = %+v, want reason %q", outcome, tt.wantReason)
+			}
+			voteJSON, _ := env.stub.GetState("VOTE_E1_" + tt.voterID)
+			if (voteJSON != nil) != outcome.Accepted {
+				t.Errorf("vote recorded = %v, want %v", voteJSON != nil, outcome.Accepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// defaultReceiptTemplate is rendered by GetVoteReceipt for elections without a receipt template
+const defaultReceiptTemplate = "Your vote in {election} was recorded at {time} in transaction {txid}"
+
+// maxReceiptTemplateLength caps the length of an election's receipt template
+const maxReceiptTemplateLength = 1024
+
+// receiptPlaceholder matches a {name} placeholder in a receipt template
+var receiptPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// receiptPlaceholders lists the placeholders a receipt template may use. The
+// selection is deliberately absent so that a receipt never reveals the vote
+var receiptPlaceholders = map[string]bool{
+	"txid":     true,
+	"time":     true,
+	"election": true,
+}
+
+// validateReceiptTemplate checks that a receipt template only uses known
+// placeholders, and in particular none that would reveal the candidate
+func validateReceiptTemplate(template string) error {
+	if len(template) > maxReceiptTemplateLength {
+		return fmt.Errorf("receipt template exceeds %d characters", maxReceiptTemplateLength)
+	}
+	for _, match := range receiptPlaceholder.FindAllStringSubmatch(template, -1) {
+		name := strings.ToLower(strings.TrimSpace(match[1]))
+		if strings.Contains(name, "candidate") {
+			return fmt.Errorf("receipt template must not reveal the candidate: %s", match[0])
+		}
+		if !receiptPlaceholders[name] {
+			return fmt.Errorf("unknown receipt template placeholder %s", match[0])
+		}
+	}
+	return nil
+}
+
+// renderReceipt fills the placeholders of an election's receipt template for a vote
+func renderReceipt(election *Election, vote *Vote) string {
+	template := election.ReceiptTemplate
+	if template == "" {
+		template = defaultReceiptTemplate
+	}
+
+	values := map[string]string{
+		"txid":     vote.TxID,
+		"time":     vote.Timestamp.UTC().Format(time.RFC3339),
+		"election": election.Name,
+	}
+	return receiptPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := strings.ToLower(strings.TrimSpace(placeholder[1 : len(placeholder)-1]))
+		return values[name]
+	})
+}
+
+// receiptSaltKey is the transient data field holding the voter's secret receipt salt
+const receiptSaltKey = "receiptSalt"
+
+// minReceiptSaltLength is the minimum length in bytes of a receipt salt, so
+// that the selection cannot be recovered by guessing the salt
+const minReceiptSaltLength = 16
+
+// getReceiptSalt returns the receipt salt supplied in the transient data. It
+// is kept out of the ledger and out of any response, which peers record
+func getReceiptSalt(ctx contractapi.TransactionContextInterface) (string, error) {
+	salt, err := getTransientValue(ctx, receiptSaltKey)
+	if err != nil {
+		return "", err
+	}
+	if len(salt) < minReceiptSaltLength {
+		return "", fmt.Errorf("a receipt salt of at least %d bytes must be supplied in the transient field %s", minReceiptSaltLength, receiptSaltKey)
+	}
+	return salt, nil
+}
+
+// voteReceiptHash returns the receipt hash of a vote. It binds the election,
+// voter, selection, timestamp and transaction ID to the voter's secret salt,
+// so that without the salt the hash cannot be matched against each candidate
+func voteReceiptHash(vote *Vote, salt string) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		vote.ElectionID,
+		vote.VoterID,
+		vote.CandidateID,
+		vote.Timestamp.UTC().Format(time.RFC3339Nano),
+		vote.TxID,
+		salt,
+	}, "|")))
+	return hex.EncodeToString(hash[:])
+}
+
+// CastVoteWithReceipt casts a vote like CastVote, returning the receipt hash
+// of an accepted vote in its outcome. The voter supplies a secret salt in the
+// transient field receiptSalt and keeps it together with the receipt hash to
+// have the vote verified later
+func (s *VotingContract) CastVoteWithReceipt(ctx contractapi.TransactionContextInterface, electionID string, voterID string, candidateID string) (*VoteOutcome, error) {
+	salt, err := getReceiptSalt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vote, err := s.castVote(ctx, electionID, voterID, candidateID, "", "")
+	outcome, err := voteOutcome(err)
+	if err != nil || !outcome.Accepted {
+		return outcome, err
+	}
+	outcome.ReceiptHash = voteReceiptHash(vote, salt)
+
+	return outcome, nil
+}
+
+// VerifyVoteReceipt reports whether a receipt hash matches the vote recorded
+// for a voter in an election, given the voter's receipt salt in the transient
+// field receiptSalt. A missing vote simply does not match
+func (s *VotingContract) VerifyVoteReceipt(ctx contractapi.TransactionContextInterface, electionID string, voterID string, receiptHash string) (bool, error) {
+	salt, err := getReceiptSalt(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	voteJSON, err := ctx.GetStub().GetState("VOTE_" + electionID + "_" + voterID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if voteJSON == nil {
+		return false, nil
+	}
+
+	var vote Vote
+	err = json.Unmarshal(voteJSON, &vote)
+	if err != nil {
+		return false, err
+	}
+
+	expected := voteReceiptHash(&vote, salt)
+	given := strings.ToLower(strings.TrimSpace(receiptHash))
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1, nil
+}
+
+// GetVoteReceipt returns the confirmation message for a voter's vote in an
+// election, rendered from the election's receipt template. The message never
+// includes the selection
+func (s *VotingContract) GetVoteReceipt(ctx contractapi.TransactionContextInterface, electionID string, voterID string) (string, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return "", err
+	}
+
+	voteJSON, err := ctx.GetStub().GetState("VOTE_" + electionID + "_" + voterID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if voteJSON == nil {
+		return "", fmt.Errorf("voter %s has not voted in election %s", voterID, electionID)
+	}
+
+	var vote Vote
+	err = json.Unmarshal(voteJSON, &vote)
+	if err != nil {
+		return "", err
+	}
+
+	return renderReceipt(election, &vote), nil
+}
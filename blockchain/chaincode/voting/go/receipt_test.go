@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// setReceiptSalt supplies the receipt salt in the transient data of later calls
+func (e *testEnv) setReceiptSalt(salt string) {
+	e.stub.TransientMap = map[string][]byte{receiptSaltKey: []byte(salt)}
+}
+
+func TestVerifyVoteReceipt(t *testing.T) {
+	const salt = "a-secret-salt-of-the-voter"
+
+	env := setupElection(t)
+	env.setReceiptSalt("short")
+	mustFail(t, env.submit(func() error {
+		_, err := env.contract.CastVoteWithReceipt(env.ctx, "E1", "V1", "C1")
+		return err
+	}), "receipt salt of at least 16 bytes")
+
+	// A refused vote carries no receipt
+	env.setReceiptSalt(salt)
+	var outcome *VoteOutcome
+	must(t, env.submit(func() (err error) {
+		outcome, err = env.contract.CastVoteWithReceipt(env.ctx, "E1", "V1", "C9")
+		return err
+	}))
+	if outcome.Accepted || outcome.ReceiptHash != "" || outcome.Reason != "candidate is not part of this election" {
+		t.Errorf("outcome = %+v, want a refusal without a receipt", outcome)
+	}
+
+	env.advance(time.Minute)
+	must(t, env.submit(func() (err error) {
+		outcome, err = env.contract.CastVoteWithReceipt(env.ctx, "E1", "V1", "C1")
+		return err
+	}))
+	receipt := outcome.ReceiptHash
+	if !outcome.Accepted || len(receipt) != 64 {
+		t.Fatalf("receipt = %q, want a SHA-256 hex digest", receipt)
+	}
+
+	tests := []struct {
+		name    string
+		salt    string
+		voterID string
+		receipt string
+		want    bool
+	}{
+		{"matching receipt", salt, "V1", receipt, true},
+		{"uppercase with spaces", salt, "V1", " " + strings.ToUpper(receipt) + " ", true},
+		{"wrong salt", salt + "-other", "V1", receipt, false},
+		{"another voter", salt, "V2", receipt, false},
+		{"altered receipt", salt, "V1", "0" + receipt[1:], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.setReceiptSalt(tt.salt)
+			ok, err := env.contract.VerifyVoteReceipt(env.ctx, "E1", tt.voterID, tt.receipt)
+			must(t, err)
+			if ok != tt.want {
+				t.Errorf("VerifyVoteReceipt = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+
+	// The receipt cannot be matched against each candidate without the salt
+	vote, err := env.contract.GetVote(env.ctx, "E1", "V1")
+	must(t, err)
+	vote.CandidateID = "C2"
+	if voteReceiptHash(vote, salt) == receipt {
+		t.Errorf("receipt does not depend on the selection")
+	}
+
+	env.setReceiptSalt("")
+	_, err = env.contract.VerifyVoteReceipt(env.ctx, "E1", "V1", receipt)
+	mustFail(t, err, "receipt salt")
+}
+
+func TestValidateReceiptTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"known placeholders", "Vote in {election} at {time}, tx {txid}", ""},
+		{"placeholder case and spacing", "Tx { TXID }", ""},
+		{"no placeholders", "Thank you for voting", ""},
+		{"candidate placeholder", "You chose {candidate}", "must not reveal the candidate"},
+		{"candidate name placeholder", "You chose {candidateName}", "must not reveal the candidate"},
+		{"unknown placeholder", "Hello {voter}", "unknown receipt template placeholder {voter}"},
+		{"too long", strings.Repeat("x", maxReceiptTemplateLength+1), "exceeds 1024 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReceiptTemplate(tt.template)
+			if tt.want == "" {
+				must(t, err)
+				return
+			}
+			mustFail(t, err, tt.want)
+		})
+	}
+}
+
+func TestGetVoteReceipt(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     func(txID string) string
+	}{
+		{"default template", "", func(txID string) string {
+			return "Your vote in Election E1 was recorded at " + testNow.UTC().Format(time.RFC3339) + " in transaction " + txID
+		}},
+		{"custom template", "Receipt {txid} ({election})", func(txID string) string {
+			return "Receipt " + txID + " (Election E1)"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1", "V2")
+			env.createElection("E1", "C1")
+			mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"receiptTemplate":"{candidate}"}`), "must not reveal the candidate")
+			env.configure("E1", `{"receiptTemplate":"`+tt.template+`"}`)
+			env.setStatus("E1", "active")
+			env.mustVote("E1", "V1", "C1")
+			txID := env.stub.TxID
+
+			receipt, err := env.contract.GetVoteReceipt(env.ctx, "E1", "V1")
+			must(t, err)
+			if receipt != tt.want(txID) {
+				t.Errorf("receipt = %q, want %q", receipt, tt.want(txID))
+			}
+			if strings.Contains(receipt, "C1") {
+				t.Errorf("receipt reveals the candidate: %q", receipt)
+			}
+
+			_, err = env.contract.GetVoteReceipt(env.ctx, "E1", "V2")
+			mustFail(t, err, "has not voted")
+		})
+	}
+}
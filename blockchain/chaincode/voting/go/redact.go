@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RedactVoter removes the personal data of a voter, their name and stored
+// credential, leaving the record marked as redacted under its ID so that vote
+// records and tallies are unaffected. A voter registered with a credential
+// stays marked as needing one and so cannot vote again. It is refused while
+// any election the voter could take part in has not ended. Admin only
+func (s *VotingContract) RedactVoter(ctx contractapi.TransactionContextInterface, voterID string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	voter, err := s.GetVoter(ctx, voterID)
+	if err != nil {
+		return err
+	}
+	if voter.Redacted {
+		return fmt.Errorf("voter %s has already been redacted", voterID)
+	}
+
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return err
+	}
+	for _, election := range elections {
+		if election.Status == "ended" {
+			continue
+		}
+		constituencies, err := s.electionConstituencies(ctx, election)
+		if err != nil {
+			return err
+		}
+		if len(constituencies) == 0 || containsString(constituencies, voter.Constituency) {
+			return fmt.Errorf("voter %s cannot be redacted while election %s has not ended", voterID, election.ID)
+		}
+	}
+
+	voter.Name = ""
+	voter.Redacted = true
+
+	voterJSON, err := json.Marshal(voter)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().DelState("CREDENTIAL_" + voterID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("VOTER_"+voterID, voterJSON)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedactVoter(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(env *testEnv)
+		voterID string
+		want    string
+	}{
+		{"after the election ended", func(env *testEnv) { env.endElection("E1") }, "V1", ""},
+		{"during the election", func(env *testEnv) {}, "V1", "cannot be redacted while election E1 has not ended"},
+		{"election not yet started", func(env *testEnv) {
+			env.endElection("E1")
+			env.createElection("E2", "C1")
+		}, "V1", "cannot be redacted while election E2 has not ended"},
+		{"election without candidates covers everyone", func(env *testEnv) {
+			env.endElection("E1")
+			env.createElection("R1")
+		}, "V1", "cannot be redacted while election R1 has not ended"},
+		{"election in another constituency", func(env *testEnv) {
+			env.endElection("E1")
+			env.registerCandidates("South", "S1")
+			env.createElection("E2", "S1")
+			env.setStatus("E2", "active")
+		}, "V1", ""},
+		{"already redacted", func(env *testEnv) {
+			env.endElection("E1")
+			must(env.t, env.contract.RedactVoter(env.ctx, "V1"))
+		}, "V1", "has already been redacted"},
+		{"unknown voter", func(env *testEnv) { env.endElection("E1") }, "V9", "does not exist"},
+		{"non-admin", func(env *testEnv) {
+			env.endElection("E1")
+			env.asUser()
+		}, "V1", "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			env.mustVote("E1", "V1", "C1")
+			tt.prepare(env)
+
+			err := env.contract.RedactVoter(env.ctx, tt.voterID)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+
+			voter, err := env.contract.GetVoter(env.ctx, tt.voterID)
+			must(t, err)
+			if !voter.Redacted || voter.Name != "" || voter.Constituency != "North" {
+				t.Errorf("voter = %+v, want a redacted record in North", voter)
+			}
+		})
+	}
+}
+
+func TestRedactVoterKeepsVotes(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C2")
+	env.endElection("E1")
+	before := candidateVotes(env.results("E1"))
+
+	must(t, env.contract.RedactVoter(env.ctx, "V1"))
+
+	vote, err := env.contract.GetVote(env.ctx, "E1", "V1")
+	must(t, err)
+	if vote.VoterID != "V1" || vote.CandidateID != "C1" {
+		t.Errorf("vote = %+v, want V1's vote for C1", vote)
+	}
+	after := candidateVotes(env.results("E1"))
+	if after["C1"] != before["C1"] || after["C2"] != before["C2"] {
+		t.Errorf("votes after redaction = %v, want %v", after, before)
+	}
+}
+
+func TestRedactedVoterFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential bool
+		want       map[string]interface{} // Every field left on the stored record
+	}{
+		{"voter without a credential", false,
+			map[string]interface{}{"id": "V5", "name": "", "constituency": "North", "hasVoted": true, "redacted": true}},
+		{"voter with a credential", true,
+			map[string]interface{}{"id": "V5", "name": "", "constituency": "North", "hasVoted": true, "hasCredential": true, "redacted": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			if tt.credential {
+				env.registerVoterWithCredential("V5", "s3cret-pin")
+				env.setCredential("s3cret-pin")
+				must(t, env.voteWithCredential("E1", "V5", "C1"))
+			} else {
+				env.registerVoters("North", "V5")
+				env.mustVote("E1", "V5", "C1")
+			}
+			env.endElection("E1")
+			must(t, env.contract.RedactVoter(env.ctx, "V5"))
+
+			voterJSON, err := env.stub.GetState("VOTER_V5")
+			must(t, err)
+			var got map[string]interface{}
+			must(t, json.Unmarshal(voterJSON, &got))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redacted record = %v, want %v", got, tt.want)
+			}
+			if credentialJSON, _ := env.stub.GetState("CREDENTIAL_V5"); credentialJSON != nil {
+				t.Errorf("credential hash kept through redaction")
+			}
+
+			// A redacted voter registered with a credential can no longer vote
+			env.createElection("E2", "C1")
+			env.setStatus("E2", "active")
+			env.setCredential("s3cret-pin")
+			err = env.voteWithCredential("E2", "V5", "C1")
+			if tt.credential {
+				mustFail(t, err, "no credential is stored for voter V5")
+			} else {
+				must(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ElectionTypeReferendum marks an election decided by yes/no options rather than candidates
+const ElectionTypeReferendum = "referendum"
+
+// Options accepted by CastVote in referendum elections
+const (
+	SelectionYes     = "YES"
+	SelectionNo      = "NO"
+	SelectionAbstain = "ABSTAIN"
+)
+
+// Referendum outcomes
+const (
+	ReferendumPassed   = "passed"
+	ReferendumRejected = "rejected"
+)
+
+// ReferendumResult represents the result of a referendum
+type ReferendumResult struct {
+	ElectionID   string  `json:"electionId"`
+	YesVotes     int     `json:"yesVotes"`
+	NoVotes      int     `json:"noVotes"`
+	AbstainVotes int     `json:"abstainVotes"`
+	TotalVotes   int     `json:"totalVotes"`
+	YesPercent   float64 `json:"yesPercent"` // Share of yes and no votes, abstentions left out
+	Outcome      string  `json:"outcome"`    // Passed with more yes than no votes, rejected otherwise
+}
+
+// isReferendumOption returns true for the options of a referendum ballot
+func isReferendumOption(selection string) bool {
+	return selection == SelectionYes || selection == SelectionNo || selection == SelectionAbstain
+}
+
+// GetReferendumResult returns the tallies and outcome of an ended referendum.
+// A tie rejects the proposal
+func (s *VotingContract) GetReferendumResult(ctx contractapi.TransactionContextInterface, electionID string) (*ReferendumResult, error) {
+	election, votes, err := s.getEndedElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	if election.Type != ElectionTypeReferendum {
+		return nil, fmt.Errorf("election %s is not a referendum", electionID)
+	}
+
+	result := ReferendumResult{
+		ElectionID: electionID,
+		Outcome:    ReferendumRejected,
+	}
+	for _, vote := range votes {
+		switch vote.CandidateID {
+		case SelectionYes:
+			result.YesVotes++
+		case SelectionNo:
+			result.NoVotes++
+		case SelectionAbstain:
+			result.AbstainVotes++
+		default:
+			continue
+		}
+		result.TotalVotes++
+	}
+
+	decided := result.YesVotes + result.NoVotes
+	if decided > 0 {
+		share := float64(result.YesVotes) * 100 / float64(decided)
+		result.YesPercent = math.Round(share*100) / 100
+	}
+	if result.YesVotes > result.NoVotes {
+		result.Outcome = ReferendumPassed
+	}
+
+	return &result, nil
+}
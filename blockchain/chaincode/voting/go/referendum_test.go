@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetReferendumResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		selections []string
+		want       ReferendumResult
+	}{
+		{"passed", []string{SelectionYes, SelectionYes, SelectionNo, SelectionAbstain},
+			ReferendumResult{YesVotes: 2, NoVotes: 1, AbstainVotes: 1, TotalVotes: 4, YesPercent: 66.67, Outcome: ReferendumPassed}},
+		{"rejected", []string{SelectionNo, SelectionNo, SelectionYes},
+			ReferendumResult{YesVotes: 1, NoVotes: 2, TotalVotes: 3, YesPercent: 33.33, Outcome: ReferendumRejected}},
+		{"tie rejects", []string{SelectionYes, SelectionNo, SelectionAbstain, SelectionAbstain},
+			ReferendumResult{YesVotes: 1, NoVotes: 1, AbstainVotes: 2, TotalVotes: 4, YesPercent: 50, Outcome: ReferendumRejected}},
+		{"only abstentions", []string{SelectionAbstain},
+			ReferendumResult{AbstainVotes: 1, TotalVotes: 1, Outcome: ReferendumRejected}},
+		{"no votes", nil, ReferendumResult{Outcome: ReferendumRejected}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("R1")
+			env.configure("R1", `{"type":"referendum"}`)
+			env.setStatus("R1", "active")
+			for i, selection := range tt.selections {
+				env.mustVote("R1", fmt.Sprintf("V%d", i+1), selection)
+			}
+			env.endElection("R1")
+
+			result, err := env.contract.GetReferendumResult(env.ctx, "R1")
+			must(t, err)
+			tt.want.ElectionID = "R1"
+			if *result != tt.want {
+				t.Errorf("result = %+v, want %+v", *result, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferendumBallot(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerVoters("North", "V1", "V2")
+	env.createElection("E1", "C1")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"type":"referendum"}`), "must not carry candidates")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"type":"plebiscite"}`), "invalid election type plebiscite")
+	env.setStatus("E1", "active")
+	mustFail(t, env.vote("E1", "V1", SelectionYes), "not part of this election")
+	env.endElection("E1")
+	_, err := env.contract.GetReferendumResult(env.ctx, "E1")
+	mustFail(t, err, "is not a referendum")
+
+	env.createElection("R1")
+	env.configure("R1", `{"type":"referendum"}`)
+	mustFail(t, env.contract.NominateCandidate(env.ctx, "R1", "C1"), "takes no candidates")
+	env.setStatus("R1", "active")
+	mustFail(t, env.vote("R1", "V1", "C1"), "referendum elections only accept YES, NO or ABSTAIN")
+	env.mustVote("R1", "V2", SelectionYes)
+	_, err = env.contract.GetReferendumResult(env.ctx, "R1")
+	mustFail(t, err, "has not ended")
+}
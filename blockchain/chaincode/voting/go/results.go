@@ -0,0 +1,685 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// getElectionVotes returns every vote recorded for the given election
+func (s *VotingContract) getElectionVotes(ctx contractapi.TransactionContextInterface, electionID string) ([]*Vote, error) {
+	prefix := "VOTE_" + electionID + "_"
+	voteIterator, err := ctx.GetStub().GetStateByRange(prefix, prefixRangeEnd(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer voteIterator.Close()
+
+	var votes []*Vote
+	for voteIterator.HasNext() {
+		queryResponse, err := voteIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var vote Vote
+		err = json.Unmarshal(queryResponse.Value, &vote)
+		if err != nil {
+			continue
+		}
+		// Election IDs may share a prefix, e.g. "E1" and "E1_B"
+		if vote.ElectionID != electionID {
+			continue
+		}
+		votes = append(votes, &vote)
+	}
+
+	return votes, nil
+}
+
+// tallyElection counts the recorded votes of an election without any status checks
+func (s *VotingContract) tallyElection(ctx contractapi.TransactionContextInterface, election *Election) (*ElectionResult, error) {
+	votes, err := s.getElectionVotes(ctx, election.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return tallyVotes(election, votes), nil
+}
+
+// isCountedVote returns false for late votes of an election that excludes them from its tally
+func isCountedVote(election *Election, vote *Vote) bool {
+	return !vote.Late || !election.ExcludeLateVotes
+}
+
+// tallyVotes counts the given votes of an election
+func tallyVotes(election *Election, votes []*Vote) *ElectionResult {
+	result := ElectionResult{
+		ElectionID:       election.ID,
+		TotalVotes:       0,
+		CandidateResults: []CandidateResult{},
+	}
+
+	// Initialize vote counts for each candidate
+	candidateVotes := make(map[string]int)
+	for _, candidateID := range election.Candidates {
+		candidateVotes[candidateID] = 0
+	}
+
+	for _, vote := range votes {
+		if !isCountedVote(election, vote) {
+			result.ExcludedLateVotes++
+			continue
+		}
+		result.TotalVotes++
+		switch {
+		case vote.CandidateID == SelectionNOTA:
+			result.NOTAVotes++
+		case vote.CandidateID == SelectionBlank:
+			result.BlankVotes++
+		case vote.CandidateID == SelectionSpoiled:
+			result.SpoiledVotes++
+		case vote.CandidateID == SelectionAbstain:
+			result.AbstainVotes++
+		case strings.HasPrefix(vote.CandidateID, writeInPrefix):
+			result.WriteInVotes++
+		default:
+			candidateVotes[vote.CandidateID]++
+		}
+	}
+
+	// Report candidates in ballot order, followed by any unlisted candidate
+	// that still received votes, so the output is deterministic
+	for _, candidateID := range election.Candidates {
+		withdrawn := isWithdrawn(election, candidateID)
+		if withdrawn {
+			result.WastedVotes += candidateVotes[candidateID]
+		}
+		result.CandidateResults = append(result.CandidateResults, CandidateResult{
+			CandidateID: candidateID,
+			Serial:      candidateSerial(election, candidateID),
+			VoteCount:   candidateVotes[candidateID],
+			Withdrawn:   withdrawn,
+		})
+		delete(candidateVotes, candidateID)
+	}
+	var unlisted []string
+	for candidateID := range candidateVotes {
+		unlisted = append(unlisted, candidateID)
+	}
+	sort.Strings(unlisted)
+	for _, candidateID := range unlisted {
+		result.CandidateResults = append(result.CandidateResults, CandidateResult{
+			CandidateID: candidateID,
+			VoteCount:   candidateVotes[candidateID],
+		})
+	}
+
+	result.InvalidVotes = result.BlankVotes + result.SpoiledVotes
+	result.ValidVotes = result.TotalVotes - result.InvalidVotes - result.AbstainVotes
+
+	// Invalid ballots and abstentions count towards turnout but not towards any share
+	if result.ValidVotes > 0 {
+		for i := range result.CandidateResults {
+			share := float64(result.CandidateResults[i].VoteCount) * 100 / float64(result.ValidVotes)
+			result.CandidateResults[i].Percentage = math.Round(share*100) / 100
+		}
+	}
+	if result.TotalVotes > 0 {
+		ratio := float64(result.ValidVotes) / float64(result.TotalVotes)
+		result.ValidVoteRatio = math.Round(ratio*10000) / 10000
+	}
+
+	assignRanks(result.CandidateResults)
+	result.ResultHash = computeResultHash(&result)
+
+	return &result
+}
+
+// assignRanks sets the standard competition rank of each candidate: tied
+// candidates share a rank and the following rank is skipped accordingly
+func assignRanks(candidateResults []CandidateResult) {
+	for i := range candidateResults {
+		rank := 1
+		for _, other := range candidateResults {
+			if other.VoteCount > candidateResults[i].VoteCount {
+				rank++
+			}
+		}
+		candidateResults[i].Rank = rank
+	}
+}
+
+// checkResultsReleasable returns an error while an election that requires
+// every constituency to close still has a constituency open
+func checkResultsReleasable(ctx contractapi.TransactionContextInterface, election *Election) error {
+	if !election.AllConstituenciesMustClose {
+		return nil
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	var constituencies []string
+	for constituency := range election.ConstituencyEndTimes {
+		constituencies = append(constituencies, constituency)
+	}
+	sort.Strings(constituencies)
+	for _, constituency := range constituencies {
+		endTime := election.ConstituencyEndTimes[constituency]
+		if now.Before(endTime) {
+			return fmt.Errorf("results are withheld until constituency %s closes at %s", constituency, endTime.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// checkLiveResultsVisible returns an error while an election that hides its
+// live results has not ended. Every function exposing tallies or selections
+// before the end must call it
+func checkLiveResultsVisible(election *Election) error {
+	if election.HideLiveResults && election.Status != "ended" {
+		return fmt.Errorf("live results of election %s are hidden until it ends", election.ID)
+	}
+	return nil
+}
+
+// ElectionNotEndedError reports that results were requested before an
+// election ended, carrying its current status so callers can tell a
+// never-started election from one still in progress
+type ElectionNotEndedError struct {
+	ElectionID string
+	Status     string
+}
+
+func (e *ElectionNotEndedError) Error() string {
+	return fmt.Sprintf("election has not ended yet (status: %s)", e.Status)
+}
+
+// getEndedElectionResults returns an election and its tally, failing unless the election has ended
+func (s *VotingContract) getEndedElectionResults(ctx contractapi.TransactionContextInterface, electionID string) (*Election, *ElectionResult, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if election.Status != "ended" {
+		return nil, nil, &ElectionNotEndedError{ElectionID: electionID, Status: election.Status}
+	}
+	err = checkResultsReleasable(ctx, election)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.tallyElection(ctx, election)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return election, result, nil
+}
+
+// sortByVotes orders candidate results by votes descending, breaking ties by serial number
+func sortByVotes(candidateResults []CandidateResult) {
+	sort.SliceStable(candidateResults, func(i, j int) bool {
+		if candidateResults[i].VoteCount != candidateResults[j].VoteCount {
+			return candidateResults[i].VoteCount > candidateResults[j].VoteCount
+		}
+		return candidateResults[i].Serial < candidateResults[j].Serial
+	})
+}
+
+// computeResultHash returns a hex SHA-256 over the canonical form of a tally.
+// Candidates are sorted by ID so the hash only changes when the counts do
+func computeResultHash(result *ElectionResult) string {
+	candidateResults := make([]CandidateResult, len(result.CandidateResults))
+	copy(candidateResults, result.CandidateResults)
+	sort.Slice(candidateResults, func(i, j int) bool {
+		return candidateResults[i].CandidateID < candidateResults[j].CandidateID
+	})
+
+	var canonical strings.Builder
+	fmt.Fprintf(&canonical, "election=%s;total=%d;nota=%d;blank=%d;writein=%d;spoiled=%d",
+		result.ElectionID, result.TotalVotes, result.NOTAVotes, result.BlankVotes, result.WriteInVotes, result.SpoiledVotes)
+	// Only included when present so that hashes of earlier results stay valid
+	if result.AbstainVotes > 0 {
+		fmt.Fprintf(&canonical, ";abstain=%d", result.AbstainVotes)
+	}
+	for _, candidateResult := range candidateResults {
+		fmt.Fprintf(&canonical, ";%s=%d", candidateResult.CandidateID, candidateResult.VoteCount)
+	}
+
+	hash := sha256.Sum256([]byte(canonical.String()))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetVote returns the vote a voter cast in an election. Admins and
+// registered observers only, for dispute handling
+func (s *VotingContract) GetVote(ctx contractapi.TransactionContextInterface, electionID string, voterID string) (*Vote, error) {
+	err := requireAdminOrObserver(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	voteJSON, err := ctx.GetStub().GetState("VOTE_" + electionID + "_" + voterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if voteJSON == nil {
+		return nil, fmt.Errorf("no vote by voter %s exists for election %s", voterID, electionID)
+	}
+
+	var vote Vote
+	err = json.Unmarshal(voteJSON, &vote)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vote, nil
+}
+
+// standingResults returns the results of the candidates still standing in an
+// election: listed on its ballot and not withdrawn. Only they can win
+func standingResults(election *Election, result *ElectionResult) []CandidateResult {
+	var standing []CandidateResult
+	for _, candidateResult := range result.CandidateResults {
+		if containsString(election.Candidates, candidateResult.CandidateID) && !candidateResult.Withdrawn {
+			standing = append(standing, candidateResult)
+		}
+	}
+	return standing
+}
+
+// GetMultiWinnerResults returns, per constituency, the standing candidates
+// winning its seats. When candidates tie for the last seat all of them are
+// returned and flagged with TiedAtSeatBoundary
+func (s *VotingContract) GetMultiWinnerResults(ctx contractapi.TransactionContextInterface, electionID string) (map[string][]CandidateResult, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := election.SeatsPerConstituency
+	if seats == 0 {
+		seats = 1
+	}
+
+	byConstituency := make(map[string][]CandidateResult)
+	for _, candidateResult := range standingResults(election, result) {
+		candidate, err := s.GetCandidate(ctx, candidateResult.CandidateID)
+		if err != nil {
+			continue // Unregistered candidates cannot win a seat
+		}
+		byConstituency[candidate.Constituency] = append(byConstituency[candidate.Constituency], candidateResult)
+	}
+
+	winners := make(map[string][]CandidateResult)
+	for constituency, candidateResults := range byConstituency {
+		sortByVotes(candidateResults)
+		if len(candidateResults) <= seats {
+			winners[constituency] = candidateResults
+			continue
+		}
+
+		// Extend past the last seat while candidates tie with it
+		cutoff := candidateResults[seats-1].VoteCount
+		end := seats
+		for end < len(candidateResults) && candidateResults[end].VoteCount == cutoff {
+			end++
+		}
+		if end > seats {
+			for i := range candidateResults[:end] {
+				if candidateResults[i].VoteCount == cutoff {
+					candidateResults[i].TiedAtSeatBoundary = true
+				}
+			}
+		}
+		winners[constituency] = candidateResults[:end]
+	}
+
+	return winners, nil
+}
+
+// LowestCandidate reports the trailing candidate of an election
+type LowestCandidate struct {
+	Candidate      CandidateResult `json:"candidate"`
+	TiedForLast    bool            `json:"tiedForLast"`
+	TiedCandidates []string        `json:"tiedCandidates"` // All candidates sharing the lowest count
+}
+
+// GetLowestCandidate returns the standing candidate with the fewest votes in
+// an ended election, for manual elimination rounds. Withdrawn and unlisted
+// candidates are already out of the running and never returned. When several
+// candidates tie for last, the one with the lowest serial is returned and
+// TiedForLast is set
+func (s *VotingContract) GetLowestCandidate(ctx contractapi.TransactionContextInterface, electionID string) (*LowestCandidate, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateResults := standingResults(election, result)
+	if len(candidateResults) == 0 {
+		return nil, fmt.Errorf("election %s has no candidates", electionID)
+	}
+	sortByVotes(candidateResults)
+
+	lowestVotes := candidateResults[len(candidateResults)-1].VoteCount
+	lowest := LowestCandidate{
+		TiedCandidates: []string{},
+	}
+	for _, candidateResult := range candidateResults {
+		if candidateResult.VoteCount != lowestVotes {
+			continue
+		}
+		if len(lowest.TiedCandidates) == 0 {
+			lowest.Candidate = candidateResult
+		}
+		lowest.TiedCandidates = append(lowest.TiedCandidates, candidateResult.CandidateID)
+	}
+	lowest.TiedForLast = len(lowest.TiedCandidates) > 1
+
+	return &lowest, nil
+}
+
+// GetElectionResultsNamed returns the results of an ended election with each
+// candidate's name, party and running mate resolved. Candidates whose record
+// is missing are reported with the name "Unknown"
+func (s *VotingContract) GetElectionResultsNamed(ctx contractapi.TransactionContextInterface, electionID string) (*ElectionResult, error) {
+	_, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.CandidateResults {
+		candidateResult := &result.CandidateResults[i]
+		candidateResult.Name = "Unknown"
+		candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateResult.CandidateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if candidateJSON == nil {
+			continue
+		}
+		var candidate Candidate
+		err = json.Unmarshal(candidateJSON, &candidate)
+		if err != nil {
+			return nil, err
+		}
+		candidateResult.Name = candidate.Name
+		candidateResult.Party = candidate.Party
+		candidateResult.RunningMateID = candidate.RunningMateID
+	}
+
+	return result, nil
+}
+
+// GetDepositForfeitures returns, in ballot order, the candidates of an ended
+// election whose share of valid votes falls below the configured deposit
+// forfeiture fraction
+func (s *VotingContract) GetDepositForfeitures(ctx contractapi.TransactionContextInterface, electionID string) ([]string, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := depositThreshold(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	forfeited := []string{}
+	for _, candidateResult := range result.CandidateResults {
+		if !containsString(election.Candidates, candidateResult.CandidateID) {
+			continue
+		}
+		if float64(candidateResult.VoteCount) < threshold {
+			forfeited = append(forfeited, candidateResult.CandidateID)
+		}
+	}
+
+	return forfeited, nil
+}
+
+// depositThreshold returns the votes below which a candidate forfeits their
+// deposit, the configured fraction of the election's valid votes
+func depositThreshold(ctx contractapi.TransactionContextInterface, result *ElectionResult) (float64, error) {
+	config, err := getConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return config.DepositForfeitureFraction * float64(result.ValidVotes), nil
+}
+
+// SeatAllocation reports the seats won by each party in an ended election
+type SeatAllocation struct {
+	Seats              map[string]int `json:"seats"`              // Seats per party, independents under "Independent"
+	TiedConstituencies []string       `json:"tiedConstituencies"` // Constituencies with a seat left unassigned by a tie
+}
+
+// GetSeatCountByParty determines the winners of each constituency of an
+// ended election and counts the seats won per party. Withdrawn and unlisted
+// candidates win no seats, so their votes credit no party. Seats contested by
+// a tie at the seat boundary are left unassigned and their constituency reported
+func (s *VotingContract) GetSeatCountByParty(ctx contractapi.TransactionContextInterface, electionID string) (*SeatAllocation, error) {
+	winners, err := s.GetMultiWinnerResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allocation := SeatAllocation{
+		Seats:              make(map[string]int),
+		TiedConstituencies: []string{},
+	}
+	for constituency, candidateResults := range winners {
+		for _, candidateResult := range candidateResults {
+			if candidateResult.TiedAtSeatBoundary {
+				if !containsString(allocation.TiedConstituencies, constituency) {
+					allocation.TiedConstituencies = append(allocation.TiedConstituencies, constituency)
+				}
+				continue
+			}
+			candidate, err := s.GetCandidate(ctx, candidateResult.CandidateID)
+			if err != nil {
+				return nil, err
+			}
+			party := candidate.Party
+			if party == "" {
+				party = independentParty
+			}
+			allocation.Seats[party]++
+		}
+	}
+	sort.Strings(allocation.TiedConstituencies)
+
+	return &allocation, nil
+}
+
+// ExportResultsCSV returns the candidate results of an ended election as CSV
+// with the header candidateId,name,party,constituency,votes,percentage
+func (s *VotingContract) ExportResultsCSV(ctx contractapi.TransactionContextInterface, electionID string) (string, error) {
+	_, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	err = writer.Write([]string{"candidateId", "name", "party", "constituency", "votes", "percentage"})
+	if err != nil {
+		return "", err
+	}
+	for _, candidateResult := range result.CandidateResults {
+		candidate := &Candidate{Name: "Unknown"}
+		candidateJSON, err := ctx.GetStub().GetState("CANDIDATE_" + candidateResult.CandidateID)
+		if err != nil {
+			return "", fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if candidateJSON != nil {
+			err = json.Unmarshal(candidateJSON, candidate)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		err = writer.Write([]string{
+			candidateResult.CandidateID,
+			candidate.Name,
+			candidate.Party,
+			candidate.Constituency,
+			strconv.Itoa(candidateResult.VoteCount),
+			strconv.FormatFloat(candidateResult.Percentage, 'f', 2, 64),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// GetCandidateMargin returns candidateA's votes minus candidateB's in an
+// ended election. Both candidates must be listed in the election
+func (s *VotingContract) GetCandidateMargin(ctx contractapi.TransactionContextInterface, electionID string, candidateA string, candidateB string) (int, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return 0, err
+	}
+	for _, candidateID := range []string{candidateA, candidateB} {
+		if !containsString(election.Candidates, candidateID) {
+			return 0, fmt.Errorf("candidate %s is not part of election %s", candidateID, electionID)
+		}
+	}
+
+	votes := make(map[string]int)
+	for _, candidateResult := range result.CandidateResults {
+		votes[candidateResult.CandidateID] = candidateResult.VoteCount
+	}
+
+	return votes[candidateA] - votes[candidateB], nil
+}
+
+// ElectionWinner reports the winner of an ended election and their lead
+type ElectionWinner struct {
+	ElectionID     string   `json:"electionId"`
+	CandidateID    string   `json:"candidateId"` // Empty while a tie for first place is unresolved
+	VoteCount      int      `json:"voteCount"`
+	Margin         int      `json:"margin"`         // Lead over the runner-up
+	IsProvisional  bool     `json:"isProvisional"`  // Margin below the election's MinWinningMargin: a recount or runoff is needed
+	Tied           bool     `json:"tied"`           // Several candidates share the top count
+	TiedCandidates []string `json:"tiedCandidates"` // Sorted; empty without a tie
+}
+
+// GetElectionWinner returns the candidate with the most votes in an ended
+// election, ignoring withdrawn candidates. A tie for first place names the
+// candidate drawn by ResolveTieByLot once the draw has been made
+func (s *VotingContract) GetElectionWinner(ctx contractapi.TransactionContextInterface, electionID string) (*ElectionWinner, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := standingResults(election, result)
+	if len(standing) == 0 {
+		return nil, fmt.Errorf("election %s has no candidates", electionID)
+	}
+	sortByVotes(standing)
+
+	winner := ElectionWinner{
+		ElectionID:     electionID,
+		CandidateID:    standing[0].CandidateID,
+		VoteCount:      standing[0].VoteCount,
+		Margin:         standing[0].VoteCount,
+		TiedCandidates: []string{},
+	}
+	if len(standing) > 1 {
+		winner.Margin = standing[0].VoteCount - standing[1].VoteCount
+	}
+
+	if winner.Margin == 0 && len(standing) > 1 {
+		winner.Tied = true
+		for _, candidateResult := range standing {
+			if candidateResult.VoteCount == winner.VoteCount {
+				winner.TiedCandidates = append(winner.TiedCandidates, candidateResult.CandidateID)
+			}
+		}
+		sort.Strings(winner.TiedCandidates)
+
+		winner.CandidateID = ""
+		tieBreakJSON, err := ctx.GetStub().GetState("TIEBREAK_" + electionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if tieBreakJSON != nil {
+			var tieBreak TieBreak
+			err = json.Unmarshal(tieBreakJSON, &tieBreak)
+			if err != nil {
+				return nil, err
+			}
+			winner.CandidateID = tieBreak.Chosen
+		}
+	}
+
+	winner.IsProvisional = winner.Margin < election.MinWinningMargin
+
+	return &winner, nil
+}
+
+// RunoffSimulation reports the two candidates who would contest a runoff
+type RunoffSimulation struct {
+	ElectionID        string          `json:"electionId"`
+	First             CandidateResult `json:"first"`
+	Second            CandidateResult `json:"second"`
+	MajorityReached   bool            `json:"majorityReached"`   // The leader already holds more than half of the counted share, so no runoff is needed
+	TiedForSecond     bool            `json:"tiedForSecond"`     // Several candidates share the second-highest count; Second has the lowest serial
+	RequiresRevote    bool            `json:"requiresRevote"`    // Plurality ballots hold no second preferences, so a real runoff needs a fresh vote
+	ProjectedWinnerID string          `json:"projectedWinnerId"` // Set only when the leader holds a majority
+}
+
+// SimulateRunoff identifies the top two candidates of an ended election for
+// a two-round runoff and reports their first-round shares. Votes record a
+// single choice, so the runoff outcome cannot be derived from them; only a
+// leader with a majority of the first round is projected as the winner
+func (s *VotingContract) SimulateRunoff(ctx contractapi.TransactionContextInterface, electionID string) (*RunoffSimulation, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := standingResults(election, result)
+	if len(standing) < 2 {
+		return nil, fmt.Errorf("election %s needs at least two candidates for a runoff", electionID)
+	}
+	sortByVotes(standing)
+
+	simulation := RunoffSimulation{
+		ElectionID:    electionID,
+		First:         standing[0],
+		Second:        standing[1],
+		TiedForSecond: len(standing) > 2 && standing[2].VoteCount == standing[1].VoteCount,
+	}
+	simulation.MajorityReached = simulation.First.Percentage > 50
+	simulation.RequiresRevote = !simulation.MajorityReached
+	if simulation.MajorityReached {
+		simulation.ProjectedWinnerID = simulation.First.CandidateID
+	}
+
+	return &simulation, nil
+}
@@ -0,0 +1,915 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    [][2]string // Voter and candidate of each vote
+		second   [][2]string
+		wantSame bool
+	}{
+		{
+			name:     "same votes in a different order",
+			first:    [][2]string{{"V1", "C1"}, {"V2", "C2"}, {"V3", "C1"}},
+			second:   [][2]string{{"V3", "C2"}, {"V1", "C1"}, {"V2", "C1"}},
+			wantSame: true,
+		},
+		{
+			name:     "one vote moved to another candidate",
+			first:    [][2]string{{"V1", "C1"}, {"V2", "C2"}},
+			second:   [][2]string{{"V1", "C1"}, {"V2", "C1"}},
+			wantSame: false,
+		},
+		{
+			name:     "one more vote",
+			first:    [][2]string{{"V1", "C1"}},
+			second:   [][2]string{{"V1", "C1"}, {"V2", "C1"}},
+			wantSame: false,
+		},
+	}
+
+	hashOf := func(t *testing.T, votes [][2]string) string {
+		env := setupElection(t)
+		for _, vote := range votes {
+			env.mustVote("E1", vote[0], vote[1])
+		}
+		env.endElection("E1")
+		return env.results("E1").ResultHash
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := hashOf(t, tt.first), hashOf(t, tt.second)
+			if first == "" || second == "" {
+				t.Fatalf("result hash is empty")
+			}
+			if (first == second) != tt.wantSame {
+				t.Errorf("hashes %s and %s: same = %v, want %v", first, second, first == second, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestComputeResultHashIgnoresCandidateOrder(t *testing.T) {
+	a := &ElectionResult{ElectionID: "E1", TotalVotes: 3, CandidateResults: []CandidateResult{
+		{CandidateID: "C1", VoteCount: 2},
+		{CandidateID: "C2", VoteCount: 1},
+	}}
+	b := &ElectionResult{ElectionID: "E1", TotalVotes: 3, CandidateResults: []CandidateResult{
+		{CandidateID: "C2", VoteCount: 1},
+		{CandidateID: "C1", VoteCount: 2},
+	}}
+
+	if computeResultHash(a) != computeResultHash(b) {
+		t.Errorf("hash depends on the order of candidate results")
+	}
+}
+
+func TestElectionResultsBreakdown(t *testing.T) {
+	env := setupElection(t)
+	env.registerVoters("North", "V5", "V6", "V7")
+	env.setStatus("E1", "created")
+	env.configure("E1", `{"allowNota":true,"allowBlank":true,"allowSpoiled":true,"allowWriteIn":true}`)
+	env.setStatus("E1", "active")
+
+	for voterID, selection := range map[string]string{
+		"V1": "C1",
+		"V2": "C1",
+		"V3": "C2",
+		"V4": SelectionNOTA,
+		"V5": SelectionBlank,
+		"V6": SelectionSpoiled,
+		"V7": writeInPrefix + "Jane Doe",
+	} {
+		env.mustVote("E1", voterID, selection)
+	}
+	env.endElection("E1")
+	result := env.results("E1")
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"total", result.TotalVotes, 7},
+		{"NOTA", result.NOTAVotes, 1},
+		{"blank", result.BlankVotes, 1},
+		{"spoiled", result.SpoiledVotes, 1},
+		{"write-in", result.WriteInVotes, 1},
+		{"invalid", result.InvalidVotes, 2},
+		{"valid", result.ValidVotes, 5},
+		{"valid ratio", result.ValidVoteRatio, 0.7143},
+		{"C1 votes", candidateVotes(result)["C1"], 2},
+		{"C1 share of valid votes", result.CandidateResults[0].Percentage, 40.0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCastVoteReservedSelectionsDisabled(t *testing.T) {
+	tests := []struct {
+		selection string
+		want      string
+	}{
+		{SelectionNOTA, "NOTA is not enabled"},
+		{SelectionBlank, "blank votes are not enabled"},
+		{SelectionSpoiled, "spoiled ballots are not enabled"},
+		{writeInPrefix + "Jane", "write-in votes are not enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selection, func(t *testing.T) {
+			env := setupElection(t)
+			mustFail(t, env.vote("E1", "V1", tt.selection), tt.want)
+		})
+	}
+}
+
+func TestGetVote(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C2")
+
+	tests := []struct {
+		name    string
+		asUser  bool
+		voterID string
+		want    string
+		wantErr string
+	}{
+		{"admin reads a vote", false, "V1", "C2", ""},
+		{"voter has not voted", false, "V2", "", "no vote by voter V2"},
+		{"caller is not an admin", true, "V1", "", "access denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.asAdmin()
+			if tt.asUser {
+				env.asUser()
+			}
+			vote, err := env.contract.GetVote(env.ctx, "E1", tt.voterID)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if vote.CandidateID != tt.want || vote.VoterID != tt.voterID || vote.ElectionID != "E1" {
+				t.Errorf("vote = %+v, want %s for %s", vote, tt.want, tt.voterID)
+			}
+		})
+	}
+}
+
+func TestGetMultiWinnerResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		seats     int
+		votes     map[string]string // Voter ID to candidate ID
+		wantNorth []string
+		wantTied  []string
+		wantSouth []string
+	}{
+		{
+			name:      "default single seat",
+			votes:     map[string]string{"V1": "C1", "V2": "C1", "V3": "C2", "S1": "C4"},
+			wantNorth: []string{"C1"},
+			wantSouth: []string{"C4"},
+		},
+		{
+			name:      "two seats",
+			seats:     2,
+			votes:     map[string]string{"V1": "C1", "V2": "C1", "V3": "C2", "V4": "C3", "V5": "C3"},
+			wantNorth: []string{"C1", "C3"},
+			wantSouth: []string{"C4"},
+		},
+		{
+			name:      "tie for the last seat",
+			seats:     2,
+			votes:     map[string]string{"V1": "C1", "V2": "C1", "V3": "C2", "V4": "C3"},
+			wantNorth: []string{"C1", "C2", "C3"},
+			wantTied:  []string{"C2", "C3"},
+			wantSouth: []string{"C4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.registerCandidates("South", "C4")
+			env.registerVoters("North", "V1", "V2", "V3", "V4", "V5")
+			env.registerVoters("South", "S1")
+			env.createElection("E1", "C1", "C2", "C3", "C4")
+			if tt.seats != 0 {
+				env.configure("E1", fmt.Sprintf(`{"seatsPerConstituency":%d}`, tt.seats))
+			}
+			env.setStatus("E1", "active")
+			for voterID, candidateID := range tt.votes {
+				env.mustVote("E1", voterID, candidateID)
+			}
+
+			_, err := env.contract.GetMultiWinnerResults(env.ctx, "E1")
+			mustFail(t, err, "has not ended")
+			env.endElection("E1")
+
+			winners, err := env.contract.GetMultiWinnerResults(env.ctx, "E1")
+			must(t, err)
+			var north, tied, south []string
+			for _, candidateResult := range winners["North"] {
+				north = append(north, candidateResult.CandidateID)
+				if candidateResult.TiedAtSeatBoundary {
+					tied = append(tied, candidateResult.CandidateID)
+				}
+			}
+			for _, candidateResult := range winners["South"] {
+				south = append(south, candidateResult.CandidateID)
+			}
+			if fmt.Sprint(north) != fmt.Sprint(tt.wantNorth) || fmt.Sprint(tied) != fmt.Sprint(tt.wantTied) || fmt.Sprint(south) != fmt.Sprint(tt.wantSouth) {
+				t.Errorf("winners North %v (tied %v), South %v; want North %v (tied %v), South %v", north, tied, south, tt.wantNorth, tt.wantTied, tt.wantSouth)
+			}
+		})
+	}
+}
+
+func TestGetLowestCandidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		votes    map[string]string // Voter ID to candidate ID
+		withdraw string
+		want     string
+		wantTied []string
+	}{
+		{"single trailing candidate", map[string]string{"V1": "C1", "V2": "C1", "V3": "C2", "V4": "C3", "V5": "C3"}, "", "C2", []string{"C2"}},
+		{"tie broken by serial", map[string]string{"V1": "C1", "V2": "C2", "V3": "C3"}, "", "C1", []string{"C1", "C2", "C3"}},
+		{"withdrawn candidate skipped", map[string]string{"V1": "C1", "V2": "C1", "V3": "C3"}, "C2", "C3", []string{"C3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.registerVoters("North", "V1", "V2", "V3", "V4", "V5")
+			env.createElection("E1", "C1", "C2", "C3")
+			if tt.withdraw != "" {
+				must(t, env.contract.WithdrawCandidate(env.ctx, "E1", tt.withdraw))
+			}
+			env.setStatus("E1", "active")
+			for voterID, candidateID := range tt.votes {
+				env.mustVote("E1", voterID, candidateID)
+			}
+			env.endElection("E1")
+
+			lowest, err := env.contract.GetLowestCandidate(env.ctx, "E1")
+			must(t, err)
+			if lowest.Candidate.CandidateID != tt.want {
+				t.Errorf("lowest candidate = %s, want %s", lowest.Candidate.CandidateID, tt.want)
+			}
+			if fmt.Sprint(lowest.TiedCandidates) != fmt.Sprint(tt.wantTied) || lowest.TiedForLast != (len(tt.wantTied) > 1) {
+				t.Errorf("tied candidates = %v (tied %v), want %v", lowest.TiedCandidates, lowest.TiedForLast, tt.wantTied)
+			}
+		})
+	}
+
+	env := newTestEnv(t)
+	env.createElection("EMPTY")
+	_, err := env.contract.GetLowestCandidate(env.ctx, "EMPTY")
+	mustFail(t, err, "has not ended")
+	env.endElection("EMPTY")
+	_, err = env.contract.GetLowestCandidate(env.ctx, "EMPTY")
+	mustFail(t, err, "has no candidates")
+}
+
+func TestResultsWithheldUntilConstituenciesClose(t *testing.T) {
+	northClose := testNow.Add(2 * time.Hour)
+	southClose := testNow.Add(3 * time.Hour)
+
+	tests := []struct {
+		name      string
+		mustClose bool
+		at        time.Time
+		want      string
+	}{
+		{"no requirement", false, testNow, ""},
+		{"both constituencies open", true, testNow, "until constituency North closes"},
+		{"one constituency open", true, northClose, "until constituency South closes"},
+		{"all constituencies closed", true, southClose, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerCandidates("South", "C2")
+			env.registerVoters("North", "V1")
+			env.createElection("E1", "C1", "C2")
+			env.configure("E1", fmt.Sprintf(`{"constituencyEndTimes":{"North":%q,"South":%q},"allConstituenciesMustClose":%v}`,
+				northClose.Format(time.RFC3339), southClose.Format(time.RFC3339), tt.mustClose))
+			env.setStatus("E1", "active")
+			env.mustVote("E1", "V1", "C1")
+			env.endElection("E1")
+
+			env.setTime(tt.at)
+			result, err := env.contract.GetElectionResults(env.ctx, "E1", true)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			if result.TotalVotes != 1 {
+				t.Errorf("total votes = %d, want 1", result.TotalVotes)
+			}
+		})
+	}
+}
+
+func TestWastedVotes(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C1")
+	env.mustVote("E1", "V3", "C2")
+	must(t, env.contract.WithdrawCandidate(env.ctx, "E1", "C1"))
+	env.endElection("E1")
+
+	result := env.results("E1")
+	if result.WastedVotes != 2 {
+		t.Errorf("wasted votes = %d, want 2", result.WastedVotes)
+	}
+	for _, candidateResult := range result.CandidateResults {
+		if candidateResult.Withdrawn != (candidateResult.CandidateID == "C1") {
+			t.Errorf("candidate %s withdrawn = %v", candidateResult.CandidateID, candidateResult.Withdrawn)
+		}
+	}
+	if votes := candidateVotes(result); votes["C1"] != 2 || votes["C2"] != 1 {
+		t.Errorf("votes = %v, want withdrawn votes still counted against C1", votes)
+	}
+}
+
+func TestGracePeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      string
+		wantTotal    int
+		wantExcluded int
+	}{
+		{"no grace period", `{}`, 1, 0},
+		{"late vote counted", `{"gracePeriodSeconds":60}`, 2, 0},
+		{"late vote excluded", `{"gracePeriodSeconds":60,"excludeLateVotes":true}`, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1", "V2", "V3")
+			env.createElection("E1", "C1")
+			env.configure("E1", tt.options)
+			env.setStatus("E1", "active")
+			grace := env.election("E1").GracePeriodSeconds
+			endTime := env.election("E1").EndTime
+
+			env.mustVote("E1", "V1", "C1")
+			env.setTime(endTime.Add(30 * time.Second))
+			err := env.vote("E1", "V2", "C1")
+			if grace == 0 {
+				mustFail(t, err, "not currently open for voting")
+			} else {
+				must(t, err)
+				vote, err := env.contract.GetVote(env.ctx, "E1", "V2")
+				must(t, err)
+				if !vote.Late {
+					t.Errorf("vote within the grace period is not flagged late")
+				}
+			}
+			env.setTime(endTime.Add(90 * time.Second))
+			mustFail(t, env.vote("E1", "V3", "C1"), "not currently open for voting")
+
+			vote, err := env.contract.GetVote(env.ctx, "E1", "V1")
+			must(t, err)
+			if vote.Late {
+				t.Errorf("vote before the end is flagged late")
+			}
+
+			env.endElection("E1")
+			result := env.results("E1")
+			if result.TotalVotes != tt.wantTotal || result.ExcludedLateVotes != tt.wantExcluded {
+				t.Errorf("total %d, excluded %d; want %d and %d", result.TotalVotes, result.ExcludedLateVotes, tt.wantTotal, tt.wantExcluded)
+			}
+		})
+	}
+}
+
+func TestElectionNotEndedError(t *testing.T) {
+	tests := []struct {
+		status string
+	}{
+		{"created"},
+		{"active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.createElection("E1")
+			if tt.status != "created" {
+				env.setStatus("E1", tt.status)
+			}
+
+			_, err := env.contract.GetElectionResults(env.ctx, "E1", true)
+			mustFail(t, err, "(status: "+tt.status+")")
+			var notEnded *ElectionNotEndedError
+			if !errors.As(err, &notEnded) {
+				t.Fatalf("error %v is not an ElectionNotEndedError", err)
+			}
+			if notEnded.ElectionID != "E1" || notEnded.Status != tt.status {
+				t.Errorf("error = %+v, want E1 in status %s", notEnded, tt.status)
+			}
+
+			_, err = env.contract.GetResultsCheckedInOnly(env.ctx, "E1")
+			if !errors.As(err, &notEnded) {
+				t.Errorf("checked-in results error %v is not an ElectionNotEndedError", err)
+			}
+		})
+	}
+}
+
+func TestGetElectionResultsNamed(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerVoters("North", "V1")
+	env.createElection("E1", "C1", "C2", "GHOST")
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C2")
+
+	_, err := env.contract.GetElectionResultsNamed(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+	env.endElection("E1")
+
+	result, err := env.contract.GetElectionResultsNamed(env.ctx, "E1")
+	must(t, err)
+
+	want := map[string][2]string{
+		"C1":    {"Name C1", "Party C1"},
+		"C2":    {"Name C2", "Party C2"},
+		"GHOST": {"Unknown", ""},
+	}
+	if len(result.CandidateResults) != len(want) {
+		t.Fatalf("got %d candidate results, want %d", len(result.CandidateResults), len(want))
+	}
+	for _, candidateResult := range result.CandidateResults {
+		w := want[candidateResult.CandidateID]
+		if candidateResult.Name != w[0] || candidateResult.Party != w[1] {
+			t.Errorf("%s resolved to %q of %q, want %q of %q", candidateResult.CandidateID, candidateResult.Name, candidateResult.Party, w[0], w[1])
+		}
+	}
+	if votes := candidateVotes(result); votes["C2"] != 1 {
+		t.Errorf("votes = %v, want C2 on 1", votes)
+	}
+}
+
+func TestAssignRanks(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes []int
+		want  []int
+	}{
+		{"distinct counts", []int{5, 3, 8}, []int{2, 3, 1}},
+		{"tie for first", []int{4, 4, 1}, []int{1, 1, 3}},
+		{"tie in the middle", []int{9, 2, 2, 1}, []int{1, 2, 2, 4}},
+		{"all tied", []int{0, 0}, []int{1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidateResults := make([]CandidateResult, len(tt.votes))
+			for i, votes := range tt.votes {
+				candidateResults[i].VoteCount = votes
+			}
+			assignRanks(candidateResults)
+			for i, candidateResult := range candidateResults {
+				if candidateResult.Rank != tt.want[i] {
+					t.Errorf("rank %d = %d, want %d", i, candidateResult.Rank, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestElectionResultsRanks(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3")
+	env.registerVoters("North", "V1", "V2", "V3")
+	env.createElection("E1", "C1", "C2", "C3")
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C2")
+	env.mustVote("E1", "V2", "C3")
+	env.mustVote("E1", "V3", "C2")
+	env.endElection("E1")
+
+	want := map[string]int{"C2": 1, "C3": 2, "C1": 3}
+	for _, candidateResult := range env.results("E1").CandidateResults {
+		if candidateResult.Rank != want[candidateResult.CandidateID] {
+			t.Errorf("rank of %s = %d, want %d", candidateResult.CandidateID, candidateResult.Rank, want[candidateResult.CandidateID])
+		}
+	}
+}
+
+func TestGetDepositForfeitures(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"default sixth of the valid votes", "", "[C3]"},
+		{"higher fraction", `{"depositForfeitureFraction":0.25}`, "[C2 C3]"},
+		{"no forfeiture", `{"depositForfeitureFraction":0}`, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6")
+			env.createElection("E1", "C1", "C2", "C3")
+			env.setStatus("E1", "active")
+			// Six valid votes: C2 reaches exactly a sixth of them
+			for _, voterID := range []string{"V1", "V2", "V3", "V4", "V5"} {
+				env.mustVote("E1", voterID, "C1")
+			}
+			env.mustVote("E1", "V6", "C2")
+			if tt.config != "" {
+				env.setConfig(tt.config)
+			}
+
+			_, err := env.contract.GetDepositForfeitures(env.ctx, "E1")
+			mustFail(t, err, "has not ended")
+			env.endElection("E1")
+
+			forfeited, err := env.contract.GetDepositForfeitures(env.ctx, "E1")
+			must(t, err)
+			if got := fmt.Sprint(forfeited); got != tt.want {
+				t.Errorf("forfeited = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSeatCountByParty(t *testing.T) {
+	env := newTestEnv(t)
+	candidates := []struct {
+		id, party, constituency string
+	}{
+		{"A1", "Party A", "North"},
+		{"B1", "Party B", "North"},
+		{"A2", "Party A", "South"},
+		{"B2", "Party B", "South"},
+		{"A3", "Party A", "East"},
+		{"I1", "", "East"},
+		{"I2", "", "West"},
+	}
+	var ids []string
+	for _, c := range candidates {
+		must(t, env.contract.RegisterCandidate(env.ctx, c.id, "Name "+c.id, c.party, c.constituency))
+		ids = append(ids, c.id)
+	}
+	votes := []struct {
+		voterID, constituency, candidateID string
+	}{
+		{"N1", "North", "A1"}, {"N2", "North", "A1"}, {"N3", "North", "B1"},
+		{"S1", "South", "B2"}, {"S2", "South", "B2"}, {"S3", "South", "A2"},
+		{"E1", "East", "A3"}, {"E2", "East", "I1"},
+		{"W1", "West", "I2"},
+	}
+	for _, v := range votes {
+		env.registerVoters(v.constituency, v.voterID)
+	}
+	env.createElection("GE", ids...)
+	env.setStatus("GE", "active")
+	for _, v := range votes {
+		env.mustVote("GE", v.voterID, v.candidateID)
+	}
+
+	_, err := env.contract.GetSeatCountByParty(env.ctx, "GE")
+	mustFail(t, err, "has not ended")
+	env.endElection("GE")
+
+	allocation, err := env.contract.GetSeatCountByParty(env.ctx, "GE")
+	must(t, err)
+	want := map[string]int{"Party A": 1, "Party B": 1, independentParty: 1}
+	if fmt.Sprint(allocation.Seats) != fmt.Sprint(want) {
+		t.Errorf("seats = %v, want %v", allocation.Seats, want)
+	}
+	if fmt.Sprint(allocation.TiedConstituencies) != "[East]" {
+		t.Errorf("tied constituencies = %v, want [East]", allocation.TiedConstituencies)
+	}
+}
+
+func TestExportResultsCSV(t *testing.T) {
+	env := newTestEnv(t)
+	must(t, env.contract.RegisterCandidate(env.ctx, "C1", "Sharma, Anita", `The "Green" Party`, "North"))
+	env.registerCandidates("North", "C2")
+	env.registerVoters("North", "V1", "V2", "V3")
+	env.createElection("E1", "C1", "C2", "GHOST")
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C1")
+	env.mustVote("E1", "V3", "C2")
+
+	_, err := env.contract.ExportResultsCSV(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+	env.endElection("E1")
+
+	export, err := env.contract.ExportResultsCSV(env.ctx, "E1")
+	must(t, err)
+	records, err := csv.NewReader(strings.NewReader(export)).ReadAll()
+	must(t, err)
+
+	want := [][]string{
+		{"candidateId", "name", "party", "constituency", "votes", "percentage"},
+		{"C1", "Sharma, Anita", `The "Green" Party`, "North", "2", "66.67"},
+		{"C2", "Name C2", "Party C2", "North", "1", "33.33"},
+		{"GHOST", "Unknown", "", "", "0", "0.00"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("export has %d rows, want %d:\n%s", len(records), len(want), export)
+	}
+	for i, row := range records {
+		if strings.Join(row, "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, row, want[i])
+		}
+	}
+}
+
+func TestValidVoteRatio(t *testing.T) {
+	tests := []struct {
+		name        string
+		selections  []string
+		wantValid   int
+		wantInvalid int
+		wantRatio   float64
+		wantShare   float64 // Of C1
+	}{
+		{"no votes", nil, 0, 0, 0, 0},
+		{"all valid", []string{"C1", "C1", SelectionNOTA}, 3, 0, 1, 66.67},
+		{"blank and spoiled", []string{"C1", "C1", SelectionNOTA, SelectionBlank, SelectionSpoiled}, 3, 2, 0.6, 66.67},
+		{"only invalid", []string{SelectionBlank, SelectionSpoiled, SelectionSpoiled}, 0, 3, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerVoters("North", "V1", "V2", "V3", "V4", "V5")
+			env.createElection("E1", "C1")
+			env.configure("E1", `{"allowNota":true,"allowBlank":true,"allowSpoiled":true}`)
+			env.setStatus("E1", "active")
+			for i, selection := range tt.selections {
+				env.mustVote("E1", fmt.Sprintf("V%d", i+1), selection)
+			}
+			env.endElection("E1")
+
+			result := env.results("E1")
+			if result.ValidVotes != tt.wantValid || result.InvalidVotes != tt.wantInvalid || result.ValidVoteRatio != tt.wantRatio {
+				t.Errorf("valid %d, invalid %d, ratio %v; want %d, %d, %v", result.ValidVotes, result.InvalidVotes, result.ValidVoteRatio, tt.wantValid, tt.wantInvalid, tt.wantRatio)
+			}
+			if result.ValidVotes+result.InvalidVotes != result.TotalVotes {
+				t.Errorf("valid and invalid votes do not add up to %d", result.TotalVotes)
+			}
+			if share := result.CandidateResults[0].Percentage; share != tt.wantShare {
+				t.Errorf("share of C1 = %v, want %v", share, tt.wantShare)
+			}
+		})
+	}
+}
+
+func TestGetCandidateMargin(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidateA string
+		candidateB string
+		want       int
+		wantErr    string
+	}{
+		{"leader first", "C1", "C2", 2, ""},
+		{"trailer first", "C2", "C1", -2, ""},
+		{"against a candidate without votes", "C2", "C3", 1, ""},
+		{"same candidate", "C1", "C1", 0, ""},
+		{"unlisted first candidate", "C9", "C1", 0, "candidate C9 is not part of election E1"},
+		{"unlisted second candidate", "C1", "C9", 0, "candidate C9 is not part of election E1"},
+	}
+
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3", "C9")
+	env.registerVoters("North", "V1", "V2", "V3", "V4")
+	env.createElection("E1", "C1", "C2", "C3")
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C1")
+	env.mustVote("E1", "V3", "C1")
+	env.mustVote("E1", "V4", "C2")
+
+	_, err := env.contract.GetCandidateMargin(env.ctx, "E1", "C1", "C2")
+	var notEnded *ElectionNotEndedError
+	if !errors.As(err, &notEnded) {
+		t.Fatalf("margin of an active election: got %v, want ElectionNotEndedError", err)
+	}
+	env.endElection("E1")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.contract.GetCandidateMargin(env.ctx, "E1", tt.candidateA, tt.candidateB)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if got != tt.want {
+				t.Errorf("GetCandidateMargin(%s, %s) = %d, want %d", tt.candidateA, tt.candidateB, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetElectionWinner(t *testing.T) {
+	tests := []struct {
+		name            string
+		minMargin       int
+		selections      []string
+		wantWinner      string
+		wantMargin      int
+		wantProvisional bool
+		wantTied        []string
+	}{
+		{"no minimum margin", 0, []string{"C1", "C1", "C2"}, "C1", 1, false, []string{}},
+		{"margin above the minimum", 2, []string{"C1", "C1", "C1", "C2"}, "C1", 2, false, []string{}},
+		{"margin below the minimum", 2, []string{"C2", "C1", "C2"}, "C2", 1, true, []string{}},
+		{"tie below the minimum", 1, []string{"C1", "C2"}, "", 0, true, []string{"C1", "C2"}},
+		{"no votes", 0, nil, "", 0, false, []string{"C1", "C2", "C3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("E1", "C1", "C2", "C3")
+			env.configure("E1", fmt.Sprintf(`{"minWinningMargin":%d}`, tt.minMargin))
+			env.setStatus("E1", "active")
+			for i, candidateID := range tt.selections {
+				env.mustVote("E1", fmt.Sprintf("V%d", i+1), candidateID)
+			}
+			env.endElection("E1")
+
+			winner, err := env.contract.GetElectionWinner(env.ctx, "E1")
+			must(t, err)
+			if winner.CandidateID != tt.wantWinner || winner.Margin != tt.wantMargin || winner.IsProvisional != tt.wantProvisional {
+				t.Errorf("winner %q, margin %d, provisional %v; want %q, %d, %v",
+					winner.CandidateID, winner.Margin, winner.IsProvisional, tt.wantWinner, tt.wantMargin, tt.wantProvisional)
+			}
+			if winner.Tied != (len(tt.wantTied) > 0) || strings.Join(winner.TiedCandidates, ",") != strings.Join(tt.wantTied, ",") {
+				t.Errorf("tied %v %v, want %v", winner.Tied, winner.TiedCandidates, tt.wantTied)
+			}
+		})
+	}
+}
+
+func TestGetElectionWinnerSkipsWithdrawnCandidates(t *testing.T) {
+	env := setupElection(t)
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C1")
+	env.mustVote("E1", "V3", "C2")
+	must(t, env.contract.WithdrawCandidate(env.ctx, "E1", "C1"))
+	env.endElection("E1")
+
+	winner, err := env.contract.GetElectionWinner(env.ctx, "E1")
+	must(t, err)
+	if winner.CandidateID != "C2" || winner.Margin != 1 {
+		t.Errorf("winner = %+v, want C2 alone with a margin of 1", winner)
+	}
+
+	env.createElection("E2", "C1")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E2", `{"minWinningMargin":-1}`), "minWinningMargin")
+}
+
+func TestAbstainVotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    string
+		wantErr    string
+		wantQuorum bool
+	}{
+		{"abstention accepted", `{"allowAbstain":true,"quorumPercent":70}`, "", true},
+		{"abstention left out of the quorum", `{"allowAbstain":true,"quorumPercent":80}`, "", false},
+		{"abstentions disabled", `{}`, "abstentions are not enabled for this election", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("E1", "C1", "C2")
+			env.configure("E1", tt.options)
+			env.setStatus("E1", "active")
+			env.mustVote("E1", "V1", "C1")
+			env.mustVote("E1", "V2", "C1")
+			env.mustVote("E1", "V3", "C2")
+			err := env.vote("E1", "V4", SelectionAbstain)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			env.endElection("E1")
+
+			result := env.results("E1")
+			if result.TotalVotes != 4 || result.AbstainVotes != 1 || result.ValidVotes != 3 || result.InvalidVotes != 0 {
+				t.Errorf("total %d, abstain %d, valid %d, invalid %d; want 4, 1, 3, 0", result.TotalVotes, result.AbstainVotes, result.ValidVotes, result.InvalidVotes)
+			}
+			if votes := candidateVotes(result); votes["C1"] != 2 || votes["C2"] != 1 {
+				t.Errorf("votes = %v, want C1 2 and C2 1", votes)
+			}
+			for _, candidateResult := range result.CandidateResults {
+				if candidateResult.CandidateID == "C1" && candidateResult.Percentage != 66.67 {
+					t.Errorf("share of C1 = %v, want 66.67 of the valid votes", candidateResult.Percentage)
+				}
+			}
+
+			// Abstentions count towards turnout but not towards the quorum
+			validity, err := env.contract.GetElectionValidity(env.ctx, "E1")
+			must(t, err)
+			if validity.TurnoutPercent != 100 || validity.QuorumTurnoutPercent != 75 || validity.QuorumMet != tt.wantQuorum {
+				t.Errorf("turnout %v, quorum turnout %v, quorum met %v; want 100, 75, %v", validity.TurnoutPercent, validity.QuorumTurnoutPercent, validity.QuorumMet, tt.wantQuorum)
+			}
+		})
+	}
+}
+
+func TestSimulateRunoff(t *testing.T) {
+	tests := []struct {
+		name          string
+		candidates    []string
+		selections    []string
+		withdraw      []string
+		wantFirst     string
+		wantSecond    string
+		wantMajority  bool
+		wantTied      bool
+		wantProjected string
+		wantErr       string
+	}{
+		{"leader with a majority", []string{"C1", "C2", "C3"}, []string{"C1", "C1", "C1", "C2"}, nil, "C1", "C2", true, false, "C1", ""},
+		{"half is no majority", []string{"C1", "C2", "C3"}, []string{"C1", "C1", "C2", "C3"}, nil, "C1", "C2", false, true, "", ""},
+		{"plurality without a majority", []string{"C1", "C2", "C3"}, []string{"C2", "C2", "C3", "C1", "C3"}, nil, "C2", "C3", false, false, "", ""},
+		{"withdrawn leader skipped", []string{"C1", "C2", "C3"}, []string{"C1", "C1", "C1", "C2", "C3", "C3"}, []string{"C1"}, "C3", "C2", false, false, "", ""},
+		{"one candidate standing", []string{"C1", "C2"}, []string{"C1", "C2"}, []string{"C2"}, "", "", false, false, "", "needs at least two candidates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", tt.candidates...)
+			env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6")
+			env.createElection("E1", tt.candidates...)
+			env.setStatus("E1", "active")
+			for i, candidateID := range tt.selections {
+				env.mustVote("E1", fmt.Sprintf("V%d", i+1), candidateID)
+			}
+			for _, candidateID := range tt.withdraw {
+				must(t, env.contract.WithdrawCandidate(env.ctx, "E1", candidateID))
+			}
+
+			_, err := env.contract.SimulateRunoff(env.ctx, "E1")
+			var notEnded *ElectionNotEndedError
+			if !errors.As(err, &notEnded) {
+				t.Fatalf("runoff of an active election: got %v, want ElectionNotEndedError", err)
+			}
+			env.endElection("E1")
+
+			simulation, err := env.contract.SimulateRunoff(env.ctx, "E1")
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if simulation.First.CandidateID != tt.wantFirst || simulation.Second.CandidateID != tt.wantSecond {
+				t.Errorf("runoff between %s and %s, want %s and %s", simulation.First.CandidateID, simulation.Second.CandidateID, tt.wantFirst, tt.wantSecond)
+			}
+			if simulation.MajorityReached != tt.wantMajority || simulation.RequiresRevote == tt.wantMajority {
+				t.Errorf("majority %v, revote %v; want majority %v", simulation.MajorityReached, simulation.RequiresRevote, tt.wantMajority)
+			}
+			if simulation.TiedForSecond != tt.wantTied || simulation.ProjectedWinnerID != tt.wantProjected {
+				t.Errorf("tied for second %v, projected %q; want %v, %q", simulation.TiedForSecond, simulation.ProjectedWinnerID, tt.wantTied, tt.wantProjected)
+			}
+		})
+	}
+}
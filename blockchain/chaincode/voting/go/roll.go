@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// VoterRoll is the snapshot of eligible voters taken when an election's roll is frozen
+type VoterRoll struct {
+	ElectionID string    `json:"electionId"`
+	VoterIDs   []string  `json:"voterIds"` // Sorted
+	FrozenAt   time.Time `json:"frozenAt"`
+}
+
+// includes returns true when the voter is part of the snapshot
+func (r *VoterRoll) includes(voterID string) bool {
+	i := sort.SearchStrings(r.VoterIDs, voterID)
+	return i < len(r.VoterIDs) && r.VoterIDs[i] == voterID
+}
+
+// getVoterRoll returns the frozen roll of an election, or nil when the roll is not frozen
+func (s *VotingContract) getVoterRoll(ctx contractapi.TransactionContextInterface, electionID string) (*VoterRoll, error) {
+	rollJSON, err := ctx.GetStub().GetState("ROLL_" + electionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if rollJSON == nil {
+		return nil, nil
+	}
+
+	var roll VoterRoll
+	err = json.Unmarshal(rollJSON, &roll)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roll, nil
+}
+
+// eligibleVoterIDs returns the sorted IDs of the currently registered voters
+// in the constituencies covered by an election. An election without
+// registered candidates does not restrict constituencies
+func (s *VotingContract) eligibleVoterIDs(ctx contractapi.TransactionContextInterface, election *Election) ([]string, error) {
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	voters, err := s.getAllVoters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	voterIDs := []string{}
+	for _, voter := range voters {
+		if len(constituencies) == 0 || containsString(constituencies, voter.Constituency) {
+			voterIDs = append(voterIDs, voter.ID)
+		}
+	}
+	sort.Strings(voterIDs)
+
+	return voterIDs, nil
+}
+
+// FreezeVoterRoll snapshots the voters eligible for an election. Once frozen,
+// CastVote only accepts voters in the snapshot. Admin only
+func (s *VotingContract) FreezeVoterRoll(ctx contractapi.TransactionContextInterface, electionID string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status == "ended" {
+		return fmt.Errorf("cannot freeze the voter roll of an ended election")
+	}
+
+	existing, err := s.getVoterRoll(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("the voter roll for election %s is already frozen", electionID)
+	}
+
+	voterIDs, err := s.eligibleVoterIDs(ctx, election)
+	if err != nil {
+		return err
+	}
+
+	frozenAt, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	roll := VoterRoll{
+		ElectionID: electionID,
+		VoterIDs:   voterIDs,
+		FrozenAt:   frozenAt,
+	}
+
+	rollJSON, err := json.Marshal(roll)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("ROLL_"+electionID, rollJSON)
+}
+
+// IsRollFrozen returns true when the voter roll of an election has been frozen
+func (s *VotingContract) IsRollFrozen(ctx contractapi.TransactionContextInterface, electionID string) (bool, error) {
+	_, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return false, err
+	}
+
+	roll, err := s.getVoterRoll(ctx, electionID)
+	if err != nil {
+		return false, err
+	}
+
+	return roll != nil, nil
+}
+
+// Turnout reports the votes cast against the number of eligible voters
+type Turnout struct {
+	ElectionID     string  `json:"electionId"`
+	VotesCast      int     `json:"votesCast"`
+	EligibleVoters int     `json:"eligibleVoters"`
+	TurnoutPercent float64 `json:"turnoutPercent"`
+	FromFrozenRoll bool    `json:"fromFrozenRoll"` // Denominator taken from the frozen roll snapshot
+}
+
+// GetStableTurnout returns the turnout of an election, using the frozen
+// voter roll as the denominator when there is one so that later changes to
+// the roll do not skew the figure
+func (s *VotingContract) GetStableTurnout(ctx contractapi.TransactionContextInterface, electionID string) (*Turnout, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.electionTurnout(ctx, election)
+}
+
+// electionTurnout computes the turnout of an election as GetStableTurnout reports it
+func (s *VotingContract) electionTurnout(ctx contractapi.TransactionContextInterface, election *Election) (*Turnout, error) {
+	votes, err := s.getElectionVotes(ctx, election.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	turnout := Turnout{
+		ElectionID: election.ID,
+		VotesCast:  len(votes),
+	}
+
+	roll, err := s.getVoterRoll(ctx, election.ID)
+	if err != nil {
+		return nil, err
+	}
+	if roll != nil {
+		turnout.EligibleVoters = len(roll.VoterIDs)
+		turnout.FromFrozenRoll = true
+	} else {
+		voterIDs, err := s.eligibleVoterIDs(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		turnout.EligibleVoters = len(voterIDs)
+	}
+
+	if turnout.EligibleVoters > 0 {
+		share := float64(turnout.VotesCast) * 100 / float64(turnout.EligibleVoters)
+		turnout.TurnoutPercent = math.Round(share*100) / 100
+	}
+
+	return &turnout, nil
+}
+
+// constituencyTurnout is the per-constituency breakdown of the votes cast in an election
+type constituencyTurnout struct {
+	cast     map[string]int // Votes by the constituency's voters, including those off the frozen roll
+	eligible map[string]int // Eligible voters of the constituency
+	offRoll  map[string]int // Votes by the constituency's voters who are not on the frozen roll
+}
+
+// getConstituencyTurnout breaks down the votes cast in an election and its
+// eligible voters by constituency. The frozen voter roll defines eligibility
+// when there is one; votes by registered voters outside it still count
+// against their constituency
+func (s *VotingContract) getConstituencyTurnout(ctx contractapi.TransactionContextInterface, election *Election) (*constituencyTurnout, error) {
+	roll, err := s.getVoterRoll(ctx, election.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	voters, err := s.getAllVoters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	voterConstituency := make(map[string]string)
+	turnout := constituencyTurnout{
+		cast:     make(map[string]int),
+		eligible: make(map[string]int),
+		offRoll:  make(map[string]int),
+	}
+	for _, voter := range voters {
+		voterConstituency[voter.ID] = voter.Constituency
+		if roll == nil || roll.includes(voter.ID) {
+			turnout.eligible[voter.Constituency]++
+		}
+	}
+
+	votes, err := s.getElectionVotes(ctx, election.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, vote := range votes {
+		constituency, ok := voterConstituency[vote.VoterID]
+		if !ok {
+			continue // Votes without a voter record cannot be credited to a constituency
+		}
+		turnout.cast[constituency]++
+		if roll != nil && !roll.includes(vote.VoterID) {
+			turnout.offRoll[constituency]++
+		}
+	}
+
+	return &turnout, nil
+}
+
+// GetOvervoteAlerts returns the sorted constituencies in which more votes
+// were cast in an election than there are eligible voters registered there,
+// or in which voters outside the frozen roll voted. The frozen voter roll
+// defines eligibility when there is one
+func (s *VotingContract) GetOvervoteAlerts(ctx contractapi.TransactionContextInterface, electionID string) ([]string, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	turnout, err := s.getConstituencyTurnout(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []string{}
+	for constituency, count := range turnout.cast {
+		if count > turnout.eligible[constituency] || turnout.offRoll[constituency] > 0 {
+			alerts = append(alerts, constituency)
+		}
+	}
+	sort.Strings(alerts)
+
+	return alerts, nil
+}
+
+// GetTurnoutAnomalies returns the sorted constituencies covered by an election
+// whose turnout differs from expectedPercent by more than tolerancePercent.
+// Constituencies without eligible voters have no turnout and are skipped
+func (s *VotingContract) GetTurnoutAnomalies(ctx contractapi.TransactionContextInterface, electionID string, expectedPercent float64, tolerancePercent float64) ([]string, error) {
+	err := validatePercentage("expectedPercent", expectedPercent)
+	if err != nil {
+		return nil, err
+	}
+	err = validatePercentage("tolerancePercent", tolerancePercent)
+	if err != nil {
+		return nil, err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	turnout, err := s.getConstituencyTurnout(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+	cast, eligible := turnout.cast, turnout.eligible
+
+	constituencies, err := s.electionConstituencies(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+	if len(constituencies) == 0 {
+		for constituency := range eligible {
+			constituencies = append(constituencies, constituency)
+		}
+		sort.Strings(constituencies)
+	}
+
+	anomalies := []string{}
+	for _, constituency := range constituencies {
+		if eligible[constituency] == 0 {
+			continue
+		}
+		turnout := float64(cast[constituency]) * 100 / float64(eligible[constituency])
+		if math.Abs(turnout-expectedPercent) > tolerancePercent {
+			anomalies = append(anomalies, constituency)
+		}
+	}
+
+	return anomalies, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestFreezeVoterRoll(t *testing.T) {
+	env := setupElection(t)
+	env.registerVoters("South", "S1")
+
+	frozen, err := env.contract.IsRollFrozen(env.ctx, "E1")
+	must(t, err)
+	if frozen {
+		t.Fatalf("roll is frozen before FreezeVoterRoll")
+	}
+
+	must(t, env.contract.FreezeVoterRoll(env.ctx, "E1"))
+	frozen, err = env.contract.IsRollFrozen(env.ctx, "E1")
+	must(t, err)
+	if !frozen {
+		t.Fatalf("roll is not frozen after FreezeVoterRoll")
+	}
+	mustFail(t, env.contract.FreezeVoterRoll(env.ctx, "E1"), "already frozen")
+
+	env.registerVoters("North", "LATE")
+
+	tests := []struct {
+		name    string
+		voterID string
+		want    string
+	}{
+		{"voter on the roll", "V1", ""},
+		{"voter registered after the freeze", "LATE", "not on the frozen voter roll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := env.vote("E1", tt.voterID, "C1")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+		})
+	}
+}
+
+func TestFreezeVoterRollRefused(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(env *testEnv)
+		want  string
+	}{
+		{"caller is not an admin", func(env *testEnv) { env.asUser() }, "access denied"},
+		{"election has ended", func(env *testEnv) { env.endElection("E1") }, "ended election"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			tt.setup(env)
+			mustFail(t, env.contract.FreezeVoterRoll(env.ctx, "E1"), tt.want)
+
+			frozen, err := env.contract.IsRollFrozen(env.ctx, "E1")
+			must(t, err)
+			if frozen {
+				t.Errorf("roll was frozen despite the refusal")
+			}
+		})
+	}
+}
+
+func TestGetStableTurnout(t *testing.T) {
+	tests := []struct {
+		name         string
+		freeze       bool
+		wantEligible int
+		wantPercent  float64
+		wantFrozen   bool
+	}{
+		{"live roll follows removals", false, 3, 33.33, false},
+		{"frozen roll keeps its denominator", true, 4, 25, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			env.registerVoters("South", "S1")
+			if tt.freeze {
+				must(t, env.contract.FreezeVoterRoll(env.ctx, "E1"))
+			}
+			env.mustVote("E1", "V1", "C1")
+			// There is no contract function to remove a voter yet
+			must(t, env.stub.DelState("VOTER_V4"))
+
+			turnout, err := env.contract.GetStableTurnout(env.ctx, "E1")
+			must(t, err)
+			if turnout.VotesCast != 1 || turnout.EligibleVoters != tt.wantEligible || turnout.TurnoutPercent != tt.wantPercent || turnout.FromFrozenRoll != tt.wantFrozen {
+				t.Errorf("turnout = %+v, want 1 of %d (%v%%), frozen %v", turnout, tt.wantEligible, tt.wantPercent, tt.wantFrozen)
+			}
+		})
+	}
+}
+
+func TestGetOvervoteAlerts(t *testing.T) {
+	tests := []struct {
+		name   string
+		freeze bool
+		late   []string // North voters registered after the roll was frozen and voting anyway
+		want   string
+	}{
+		{"no roll", false, nil, "[]"},
+		{"frozen roll respected", true, nil, "[]"},
+		{"more votes than eligible voters", true, []string{"L1", "L2"}, "[North]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.registerCandidates("South", "C2")
+			env.registerVoters("North", "V1", "V2")
+			env.registerVoters("South", "S1")
+			env.createElection("E1", "C1", "C2")
+			env.setStatus("E1", "active")
+			if tt.freeze {
+				must(t, env.contract.FreezeVoterRoll(env.ctx, "E1"))
+			}
+			env.mustVote("E1", "V1", "C1")
+			env.mustVote("E1", "V2", "C1")
+			env.mustVote("E1", "S1", "C2")
+
+			// CastVote refuses voters off the frozen roll, so their votes can
+			// only appear through records written behind the contract's back
+			env.registerVoters("North", tt.late...)
+			for _, voterID := range tt.late {
+				voteJSON, err := json.Marshal(Vote{ElectionID: "E1", VoterID: voterID, CandidateID: "C1", Timestamp: testNow})
+				must(t, err)
+				must(t, env.stub.PutState("VOTE_E1_"+voterID, voteJSON))
+			}
+
+			alerts, err := env.contract.GetOvervoteAlerts(env.ctx, "E1")
+			must(t, err)
+			if got := fmt.Sprint(alerts); got != tt.want {
+				t.Errorf("alerts = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTurnoutAnomalies(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  float64
+		tolerance float64
+		want      []string
+		wantErr   string
+	}{
+		{"North in range, South anomalous", 50, 10, []string{"South"}, ""},
+		{"North anomalous, South in range", 80, 25, []string{"North"}, ""},
+		{"both anomalous", 75, 5, []string{"North", "South"}, ""},
+		{"deviation equal to the tolerance", 75, 25, []string{}, ""},
+		{"expected turnout out of range", 101, 10, nil, "expectedPercent"},
+		{"negative tolerance", 50, -1, nil, "tolerancePercent"},
+	}
+
+	// North turns out 50% and South 100%; East has no voters and is skipped
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerCandidates("South", "C2")
+	env.registerCandidates("East", "C3")
+	env.registerVoters("North", "V1", "V2", "V3", "V4")
+	env.registerVoters("South", "V5", "V6")
+	env.createElection("E1", "C1", "C2", "C3")
+	env.setStatus("E1", "active")
+	for _, voterID := range []string{"V1", "V2", "V5", "V6"} {
+		env.mustVote("E1", voterID, "C1")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.contract.GetTurnoutAnomalies(env.ctx, "E1", tt.expected, tt.tolerance)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("anomalies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// sampleElectionID is the ID of the test election created by SeedSampleData
+const sampleElectionID = "SAMPLE_ELECTION"
+
+// sampleCandidates and sampleVoters are the records created by SeedSampleData
+var (
+	sampleCandidates = []Candidate{
+		{ID: "SAMPLE_C1", Name: "Asha Rao", Party: "Sample Party A", Constituency: "Sample North"},
+		{ID: "SAMPLE_C2", Name: "Vikram Singh", Party: "Sample Party B", Constituency: "Sample North"},
+		{ID: "SAMPLE_C3", Name: "Meera Iyer", Party: "Sample Party A", Constituency: "Sample South"},
+	}
+	sampleVoters = []Voter{
+		{ID: "SAMPLE_V1", Name: "Sample Voter 1", Constituency: "Sample North"},
+		{ID: "SAMPLE_V2", Name: "Sample Voter 2", Constituency: "Sample North"},
+		{ID: "SAMPLE_V3", Name: "Sample Voter 3", Constituency: "Sample South"},
+	}
+)
+
+// sampleVotingDays is how long the sample election stays open
+const sampleVotingDays = 7
+
+// SeedSampleData creates an active test election with sample candidates and
+// voters so that a fresh development network is immediately usable. It does
+// nothing when the sample election already exists, and never overwrites
+// existing candidate or voter records. Admin only, and refused unless the
+// allowSampleData config setting is enabled
+func (s *VotingContract) SeedSampleData(ctx contractapi.TransactionContextInterface) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	config, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !config.AllowSampleData {
+		return fmt.Errorf("sample data is disabled; enable allowSampleData in the config on development networks only")
+	}
+
+	exists, err := s.ElectionExists(ctx, sampleElectionID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range sampleCandidates {
+		err = putIfAbsent(ctx, "CANDIDATE_"+candidate.ID, candidate)
+		if err != nil {
+			return err
+		}
+	}
+	for _, voter := range sampleVoters {
+		err = putIfAbsent(ctx, "VOTER_"+voter.ID, voter)
+		if err != nil {
+			return err
+		}
+	}
+
+	candidateIDs := make([]string, 0, len(sampleCandidates))
+	serials := make(map[string]int)
+	for i, candidate := range sampleCandidates {
+		candidateIDs = append(candidateIDs, candidate.ID)
+		serials[candidate.ID] = i + 1
+	}
+
+	// The election is built directly rather than through CreateElection and
+	// ConfigureElection, as reads within a transaction do not see its writes
+	election := Election{
+		ID:               sampleElectionID,
+		Name:             "Sample Election",
+		Description:      "Sample data for development networks",
+		StartTime:        now,
+		EndTime:          now.Add(sampleVotingDays * 24 * time.Hour),
+		Status:           "active",
+		Candidates:       candidateIDs,
+		CandidateSerials: serials,
+		TestMode:         true,
+	}
+
+	return s.putElection(ctx, &election)
+}
+
+// putIfAbsent writes a record under key unless one already exists
+func putIfAbsent(ctx contractapi.TransactionContextInterface, key string, record interface{}) error {
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, recordJSON)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSeedSampleData(t *testing.T) {
+	env := newTestEnv(t)
+	mustFail(t, env.contract.SeedSampleData(env.ctx), "sample data is disabled")
+	env.setConfig(`{"allowSampleData":true}`)
+
+	env.asUser()
+	mustFail(t, env.contract.SeedSampleData(env.ctx), "access denied")
+	env.asAdmin()
+
+	// An existing record is never overwritten
+	must(t, env.contract.RegisterVoter(env.ctx, "SAMPLE_V1", "Real Voter", "Real Constituency"))
+
+	must(t, env.contract.SeedSampleData(env.ctx))
+	election := env.election(sampleElectionID)
+	if election.Status != "active" || !election.TestMode || len(election.Candidates) != len(sampleCandidates) {
+		t.Errorf("sample election = %+v, want an active test election with every sample candidate", election)
+	}
+	for _, candidate := range sampleCandidates {
+		_, err := env.contract.GetCandidate(env.ctx, candidate.ID)
+		must(t, err)
+	}
+	voter, err := env.contract.GetVoter(env.ctx, "SAMPLE_V1")
+	must(t, err)
+	if voter.Name != "Real Voter" {
+		t.Errorf("existing voter was overwritten: %+v", voter)
+	}
+
+	// The sample election is usable straight away and seeding again is a no-op
+	env.mustVote(sampleElectionID, "SAMPLE_V2", "SAMPLE_C1")
+	must(t, env.contract.SeedSampleData(env.ctx))
+	if version := env.election(sampleElectionID).Version; version != election.Version {
+		t.Errorf("seeding again rewrote the sample election")
+	}
+
+	elections, err := env.contract.GetAllElections(env.ctx, false)
+	must(t, err)
+	if len(elections) != 0 {
+		t.Errorf("sample election is listed by default queries")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CandidateListSeal records the hash of an election's candidate list at the time it was sealed
+type CandidateListSeal struct {
+	ElectionID string    `json:"electionId"`
+	ListHash   string    `json:"listHash"`
+	SealedAt   time.Time `json:"sealedAt"`
+}
+
+// candidateListHash returns a hash of an election's candidates in ballot
+// order together with their serial numbers
+func candidateListHash(election *Election) string {
+	hash := sha256.New()
+	for _, candidateID := range election.Candidates {
+		hash.Write([]byte(candidateID + "|" + strconv.Itoa(candidateSerial(election, candidateID)) + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// getCandidateListSeal returns the seal of an election, or nil when it has not been sealed
+func (s *VotingContract) getCandidateListSeal(ctx contractapi.TransactionContextInterface, electionID string) (*CandidateListSeal, error) {
+	sealJSON, err := ctx.GetStub().GetState("SEAL_" + electionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if sealJSON == nil {
+		return nil, nil
+	}
+
+	var seal CandidateListSeal
+	err = json.Unmarshal(sealJSON, &seal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &seal, nil
+}
+
+// SealElection stores a hash of an election's candidate list under
+// SEAL_<electionID>, so that later changes to the list can be detected
+// with VerifyCandidateListIntegrity. An election can only be sealed once. Admin only
+func (s *VotingContract) SealElection(ctx contractapi.TransactionContextInterface, electionID string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.getCandidateListSeal(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("election %s has already been sealed", electionID)
+	}
+
+	sealedAt, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	sealJSON, err := json.Marshal(CandidateListSeal{
+		ElectionID: electionID,
+		ListHash:   candidateListHash(election),
+		SealedAt:   sealedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState("SEAL_"+electionID, sealJSON)
+}
+
+// VerifyCandidateListIntegrity reports whether the candidate list of a
+// sealed election, including ballot serial numbers, still matches the hash
+// stored when it was sealed
+func (s *VotingContract) VerifyCandidateListIntegrity(ctx contractapi.TransactionContextInterface, electionID string) (bool, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return false, err
+	}
+
+	seal, err := s.getCandidateListSeal(ctx, electionID)
+	if err != nil {
+		return false, err
+	}
+	if seal == nil {
+		return false, fmt.Errorf("election %s has not been sealed", electionID)
+	}
+
+	return candidateListHash(election) == seal.ListHash, nil
+}
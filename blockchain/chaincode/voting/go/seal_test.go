@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVerifyCandidateListIntegrity(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(election *Election)
+		want   bool
+	}{
+		{"intact list", func(election *Election) {}, true},
+		{"unrelated field changed", func(election *Election) { election.Description = "edited" }, true},
+		{"candidate added", func(election *Election) { election.Candidates = append(election.Candidates, "C3") }, false},
+		{"candidate removed", func(election *Election) { election.Candidates = election.Candidates[:1] }, false},
+		{"candidates reordered", func(election *Election) {
+			election.Candidates = []string{"C2", "C1"}
+			election.CandidateSerials = nil
+		}, false},
+		{"serial changed", func(election *Election) { election.CandidateSerials["C2"] = 7 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			env.registerCandidates("North", "C3")
+			must(t, env.contract.SealElection(env.ctx, "E1"))
+
+			// Write the election straight to the ledger, as tampering would
+			election := env.election("E1")
+			tt.tamper(election)
+			electionJSON, err := json.Marshal(election)
+			must(t, err)
+			must(t, env.stub.PutState("E1", electionJSON))
+
+			intact, err := env.contract.VerifyCandidateListIntegrity(env.ctx, "E1")
+			must(t, err)
+			if intact != tt.want {
+				t.Errorf("intact = %v, want %v", intact, tt.want)
+			}
+		})
+	}
+}
+
+func TestSealElectionRefused(t *testing.T) {
+	env := setupElection(t)
+
+	_, err := env.contract.VerifyCandidateListIntegrity(env.ctx, "E1")
+	mustFail(t, err, "has not been sealed")
+	env.asUser()
+	mustFail(t, env.contract.SealElection(env.ctx, "E1"), "access denied")
+	env.asAdmin()
+	must(t, env.contract.SealElection(env.ctx, "E1"))
+	mustFail(t, env.contract.SealElection(env.ctx, "E1"), "already been sealed")
+	mustFail(t, env.contract.SealElection(env.ctx, "MISSING"), "does not exist")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ResultSnapshot is a tally of an election published at a point during counting
+type ResultSnapshot struct {
+	ElectionID string          `json:"electionId"`
+	Label      string          `json:"label"`
+	TakenAt    time.Time       `json:"takenAt"`
+	Result     *ElectionResult `json:"result"`
+}
+
+// SnapshotResults stores the current tally of an active or ended election
+// under SNAPSHOT_<electionID>_<label> and raises any vote-share alerts it
+// reveals. Labels must be unique per election, and elections hiding their
+// live results can only be snapshotted once ended. Admin only
+func (s *VotingContract) SnapshotResults(ctx contractapi.TransactionContextInterface, electionID string, label string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("snapshot label must not be empty")
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return err
+	}
+	if election.Status == "created" {
+		return fmt.Errorf("election %s has not started yet", electionID)
+	}
+	err = checkLiveResultsVisible(election)
+	if err != nil {
+		return err
+	}
+
+	snapshotKey := "SNAPSHOT_" + electionID + "_" + label
+	snapshotJSON, err := ctx.GetStub().GetState(snapshotKey)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if snapshotJSON != nil {
+		return fmt.Errorf("snapshot %s of election %s already exists", label, electionID)
+	}
+
+	result, err := s.tallyElection(ctx, election)
+	if err != nil {
+		return err
+	}
+
+	takenAt, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshotJSON, err = json.Marshal(ResultSnapshot{
+		ElectionID: electionID,
+		Label:      label,
+		TakenAt:    takenAt,
+		Result:     result,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutState(snapshotKey, snapshotJSON)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.recordThresholdCrossings(ctx, election, result)
+	return err
+}
+
+// GetResultSnapshots returns the result snapshots of an election, oldest
+// first. Snapshots of an election hiding its live results are withheld until it ends
+func (s *VotingContract) GetResultSnapshots(ctx contractapi.TransactionContextInterface, electionID string) ([]*ResultSnapshot, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkLiveResultsVisible(election)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "SNAPSHOT_" + electionID + "_"
+	snapshotIterator, err := ctx.GetStub().GetStateByRange(prefix, prefixRangeEnd(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer snapshotIterator.Close()
+
+	snapshots := []*ResultSnapshot{}
+	for snapshotIterator.HasNext() {
+		queryResponse, err := snapshotIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var snapshot ResultSnapshot
+		err = json.Unmarshal(queryResponse.Value, &snapshot)
+		if err != nil {
+			continue
+		}
+		// Election IDs may share a prefix, e.g. "E1" and "E1_B"
+		if snapshot.ElectionID != electionID {
+			continue
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].TakenAt.Before(snapshots[j].TakenAt)
+	})
+
+	return snapshots, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultSnapshots(t *testing.T) {
+	env := setupElection(t)
+	env.createElection("E1_B", "C1")
+	env.setStatus("E1_B", "active")
+	env.mustVote("E1", "V1", "C1")
+	env.advance(time.Minute)
+	must(t, env.contract.SnapshotResults(env.ctx, "E1", "first"))
+	must(t, env.contract.SnapshotResults(env.ctx, "E1_B", "first"))
+
+	env.mustVote("E1", "V2", "C2")
+	env.mustVote("E1", "V3", "C2")
+	env.advance(time.Minute)
+	must(t, env.contract.SnapshotResults(env.ctx, "E1", "second"))
+	mustFail(t, env.contract.SnapshotResults(env.ctx, "E1", "second"), "already exists")
+
+	snapshots, err := env.contract.GetResultSnapshots(env.ctx, "E1")
+	must(t, err)
+	want := []struct {
+		label string
+		votes map[string]int
+	}{
+		{"first", map[string]int{"C1": 1, "C2": 0}},
+		{"second", map[string]int{"C1": 1, "C2": 2}},
+	}
+	if len(snapshots) != len(want) {
+		t.Fatalf("got %d snapshots, want %d", len(snapshots), len(want))
+	}
+	for i, snapshot := range snapshots {
+		if snapshot.ElectionID != "E1" || snapshot.Label != want[i].label {
+			t.Errorf("snapshot %d = %s/%s, want E1/%s", i, snapshot.ElectionID, snapshot.Label, want[i].label)
+		}
+		votes := candidateVotes(snapshot.Result)
+		for candidateID, count := range want[i].votes {
+			if votes[candidateID] != count {
+				t.Errorf("snapshot %s: %s has %d votes, want %d", snapshot.Label, candidateID, votes[candidateID], count)
+			}
+		}
+	}
+	if !snapshots[0].TakenAt.Before(snapshots[1].TakenAt) {
+		t.Errorf("snapshots are not ordered by time: %v, %v", snapshots[0].TakenAt, snapshots[1].TakenAt)
+	}
+
+	// The tally of an ended election can still be snapshotted
+	env.endElection("E1")
+	must(t, env.contract.SnapshotResults(env.ctx, "E1", "final"))
+}
+
+func TestSnapshotResultsRefused(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(env *testEnv)
+		electionID string
+		label      string
+		want       string
+	}{
+		{"empty label", func(env *testEnv) {}, "E1", " ", "label must not be empty"},
+		{"non-admin", func(env *testEnv) { env.asUser() }, "E1", "first", "access denied"},
+		{"election not started", func(env *testEnv) {
+			env.createElection("E2", "C1")
+		}, "E2", "first", "has not started yet"},
+		{"live results hidden", func(env *testEnv) {
+			env.createElection("E2", "C1")
+			env.configure("E2", `{"hideLiveResults":true}`)
+			env.setStatus("E2", "active")
+		}, "E2", "first", "hidden until it ends"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.createElection("E1", "C1")
+			env.setStatus("E1", "active")
+			tt.prepare(env)
+
+			mustFail(t, env.contract.SnapshotResults(env.ctx, tt.electionID, tt.label), tt.want)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// GlobalStats aggregates figures across all ended elections
+type GlobalStats struct {
+	EndedElections          int     `json:"endedElections"`
+	TotalVotesCast          int     `json:"totalVotesCast"`
+	AverageTurnoutPercent   float64 `json:"averageTurnoutPercent"`   // Mean over elections with eligible voters
+	MostContestedElection   string  `json:"mostContestedElection"`   // Ended election with the most candidates, empty when there is none
+	MostContestedCandidates int     `json:"mostContestedCandidates"` // Candidates standing in the most contested election
+}
+
+// GetGlobalStatistics returns aggregate statistics across all ended
+// elections, for annual reports. Test elections are left out
+func (s *VotingContract) GetGlobalStatistics(ctx contractapi.TransactionContextInterface) (*GlobalStats, error) {
+	elections, err := s.GetAllElections(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := GlobalStats{}
+	turnoutSum := 0.0
+	turnoutCount := 0
+	for _, election := range elections {
+		if election.Status != "ended" {
+			continue
+		}
+		stats.EndedElections++
+
+		turnout, err := s.electionTurnout(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		stats.TotalVotesCast += turnout.VotesCast
+		if turnout.EligibleVoters > 0 {
+			turnoutSum += float64(turnout.VotesCast) * 100 / float64(turnout.EligibleVoters)
+			turnoutCount++
+		}
+
+		// Ties go to the election with the lowest ID, as elections are listed in key order
+		if len(election.Candidates) > stats.MostContestedCandidates {
+			stats.MostContestedElection = election.ID
+			stats.MostContestedCandidates = len(election.Candidates)
+		}
+	}
+
+	if turnoutCount > 0 {
+		stats.AverageTurnoutPercent = math.Round(turnoutSum/float64(turnoutCount)*100) / 100
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestGetGlobalStatistics(t *testing.T) {
+	env := newTestEnv(t)
+
+	stats, err := env.contract.GetGlobalStatistics(env.ctx)
+	must(t, err)
+	if *stats != (GlobalStats{}) {
+		t.Errorf("statistics of an empty ledger = %+v, want zero values", *stats)
+	}
+
+	env.registerCandidates("North", "C1", "C2")
+	env.registerCandidates("South", "C3")
+	env.registerVoters("North", "V1", "V2", "V3", "V4")
+	env.registerVoters("South", "V5", "V6")
+
+	elections := []struct {
+		id         string
+		candidates []string
+		options    string
+		voters     []string
+		end        bool
+	}{
+		{"E1", []string{"C1", "C2"}, `{}`, []string{"V1", "V2", "V3"}, true}, // 75% turnout
+		{"E2", []string{"C3"}, `{}`, []string{"V5"}, true},                   // 50% turnout
+		{"E3", []string{"C1", "C2", "C3"}, `{}`, []string{"V4"}, false},      // Still active
+		{"E4", []string{"C2", "C3"}, `{}`, nil, true},                        // Ties E1 on candidates, 0% turnout
+		{"E5", []string{"C1", "C2", "C3"}, `{"testMode":true}`, []string{"V6"}, true},
+	}
+	for _, election := range elections {
+		env.createElection(election.id, election.candidates...)
+		env.configure(election.id, election.options)
+		env.setStatus(election.id, "active")
+		for _, voterID := range election.voters {
+			env.mustVote(election.id, voterID, election.candidates[0])
+		}
+		if election.end {
+			env.endElection(election.id)
+		}
+	}
+
+	stats, err = env.contract.GetGlobalStatistics(env.ctx)
+	must(t, err)
+	want := GlobalStats{
+		EndedElections:          3,
+		TotalVotesCast:          4,
+		AverageTurnoutPercent:   41.67,
+		MostContestedElection:   "E1",
+		MostContestedCandidates: 2,
+	}
+	if *stats != want {
+		t.Errorf("statistics = %+v, want %+v", *stats, want)
+	}
+}
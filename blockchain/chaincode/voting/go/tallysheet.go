@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TallySheetEntry is one candidate line of a tally sheet
+type TallySheetEntry struct {
+	Serial          int     `json:"serial"`
+	CandidateID     string  `json:"candidateId"`
+	CandidateName   string  `json:"candidateName"`
+	Party           string  `json:"party"`
+	VoteCount       int     `json:"voteCount"`
+	Percentage      float64 `json:"percentage"`
+	VoteRecordCount int     `json:"voteRecordCount"` // Distinct vote records backing the count
+}
+
+// TallySheet is the per-candidate tally published by returning officers
+type TallySheet struct {
+	ElectionID   string            `json:"electionId"`
+	ElectionName string            `json:"electionName"`
+	Entries      []TallySheetEntry `json:"entries"`
+	OtherBallots int               `json:"otherBallots"` // NOTA, blank, write-in, spoiled and abstain ballots
+	TotalVotes   int               `json:"totalVotes"`
+	Reconciled   bool              `json:"reconciled"` // Entries and other ballots add up to the total
+	PrintedLines []string          `json:"printedLines"`
+}
+
+// GetTallySheet returns a printable per-candidate tally of an ended election
+func (s *VotingContract) GetTallySheet(ctx contractapi.TransactionContextInterface, electionID string) (*TallySheet, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	// Only the votes counted in the tally back its figures
+	records := make(map[string]map[string]bool)
+	for _, vote := range votes {
+		if !isCountedVote(election, vote) {
+			continue
+		}
+		if records[vote.CandidateID] == nil {
+			records[vote.CandidateID] = make(map[string]bool)
+		}
+		records[vote.CandidateID][vote.VoterID] = true
+	}
+
+	sheet := TallySheet{
+		ElectionID:   electionID,
+		ElectionName: election.Name,
+		Entries:      []TallySheetEntry{},
+		OtherBallots: result.NOTAVotes + result.BlankVotes + result.WriteInVotes + result.SpoiledVotes + result.AbstainVotes,
+		TotalVotes:   result.TotalVotes,
+		PrintedLines: []string{
+			fmt.Sprintf("Tally sheet for %s (%s)", election.Name, electionID),
+			fmt.Sprintf("%-6s %-24s %-16s %8s %8s", "Serial", "Candidate", "Party", "Votes", "Share"),
+		},
+	}
+
+	reconciled := true
+	counted := sheet.OtherBallots
+	for _, candidateResult := range result.CandidateResults {
+		entry := TallySheetEntry{
+			Serial:          candidateResult.Serial,
+			CandidateID:     candidateResult.CandidateID,
+			CandidateName:   "Unknown",
+			VoteCount:       candidateResult.VoteCount,
+			Percentage:      candidateResult.Percentage,
+			VoteRecordCount: len(records[candidateResult.CandidateID]),
+		}
+		candidate, err := s.GetCandidate(ctx, candidateResult.CandidateID)
+		if err == nil {
+			entry.CandidateName = candidate.Name
+			entry.Party = candidate.Party
+		}
+
+		if entry.VoteRecordCount != entry.VoteCount {
+			reconciled = false
+		}
+		counted += entry.VoteCount
+
+		sheet.Entries = append(sheet.Entries, entry)
+		sheet.PrintedLines = append(sheet.PrintedLines, fmt.Sprintf("%-6d %-24s %-16s %8d %7.2f%%",
+			entry.Serial, entry.CandidateName, entry.Party, entry.VoteCount, entry.Percentage))
+	}
+	sheet.Reconciled = reconciled && counted == sheet.TotalVotes
+
+	sheet.PrintedLines = append(sheet.PrintedLines,
+		fmt.Sprintf("Other ballots: %d", sheet.OtherBallots),
+		fmt.Sprintf("Total ballots: %d", sheet.TotalVotes))
+
+	return &sheet, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetTallySheet(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerVoters("North", "V1", "V2", "V3", "V4")
+	env.createElection("E1", "C2", "C1")
+	env.configure("E1", `{"allowNota":true}`)
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C1")
+	env.mustVote("E1", "V2", "C1")
+	env.mustVote("E1", "V3", "C2")
+	env.mustVote("E1", "V4", SelectionNOTA)
+
+	_, err := env.contract.GetTallySheet(env.ctx, "E1")
+	mustFail(t, err, "has not ended")
+	env.endElection("E1")
+
+	sheet, err := env.contract.GetTallySheet(env.ctx, "E1")
+	must(t, err)
+	if sheet.TotalVotes != 4 || sheet.OtherBallots != 1 || !sheet.Reconciled {
+		t.Errorf("sheet totals = %d votes, %d other, reconciled %v; want 4, 1, true", sheet.TotalVotes, sheet.OtherBallots, sheet.Reconciled)
+	}
+
+	want := map[string]struct {
+		serial int
+		votes  int
+	}{
+		"C2": {1, 1},
+		"C1": {2, 2},
+	}
+	if len(sheet.Entries) != len(want) {
+		t.Fatalf("sheet has %d entries, want %d", len(sheet.Entries), len(want))
+	}
+	for _, entry := range sheet.Entries {
+		w := want[entry.CandidateID]
+		if entry.Serial != w.serial || entry.VoteCount != w.votes || entry.VoteRecordCount != w.votes {
+			t.Errorf("entry %s = serial %d, %d votes from %d records; want serial %d, %d votes", entry.CandidateID, entry.Serial, entry.VoteCount, entry.VoteRecordCount, w.serial, w.votes)
+		}
+		if entry.CandidateName != "Name "+entry.CandidateID || entry.Party != "Party "+entry.CandidateID {
+			t.Errorf("entry %s is not resolved to the candidate record: %+v", entry.CandidateID, entry)
+		}
+	}
+
+	printed := strings.Join(sheet.PrintedLines, "\n")
+	for _, line := range []string{"Tally sheet for Election E1 (E1)", "Other ballots: 1", "Total ballots: 4"} {
+		if !strings.Contains(printed, line) {
+			t.Errorf("printed sheet lacks %q:\n%s", line, printed)
+		}
+	}
+	if strings.Contains(printed, "V1") || strings.Contains(printed, "V2") {
+		t.Errorf("printed sheet reveals voter IDs:\n%s", printed)
+	}
+}
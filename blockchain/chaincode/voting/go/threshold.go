@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// candidateThresholdEvent is the name of the chaincode event emitted when
+// candidates cross a vote-share alert threshold
+const candidateThresholdEvent = "CandidateThreshold"
+
+// ThresholdCrossing records a candidate reaching a vote-share alert threshold
+type ThresholdCrossing struct {
+	ElectionID  string  `json:"electionId"`
+	CandidateID string  `json:"candidateId"`
+	Threshold   float64 `json:"threshold"`  // Percentage of valid votes
+	Percentage  float64 `json:"percentage"` // Share at the time the crossing was detected
+}
+
+// thresholdMarkerKey returns the key marking that a candidate has already
+// been reported as crossing a threshold
+func thresholdMarkerKey(electionID string, candidateID string, threshold float64) string {
+	return "THRESHOLD_" + electionID + "_" + candidateID + "_" + strconv.FormatFloat(threshold, 'f', -1, 64)
+}
+
+// recordThresholdCrossings finds the candidates of a tally that reached one
+// of the election's alert thresholds for the first time, marks them so each
+// candidate and threshold is reported once, and emits a single
+// CandidateThreshold event listing them
+func (s *VotingContract) recordThresholdCrossings(ctx contractapi.TransactionContextInterface, election *Election, result *ElectionResult) ([]ThresholdCrossing, error) {
+	crossings := []ThresholdCrossing{}
+	if len(election.ShareAlertThresholds) == 0 {
+		return crossings, nil
+	}
+
+	thresholds := append([]float64{}, election.ShareAlertThresholds...)
+	sort.Float64s(thresholds)
+	for _, candidateResult := range result.CandidateResults {
+		for _, threshold := range thresholds {
+			if candidateResult.Percentage < threshold {
+				break
+			}
+			markerKey := thresholdMarkerKey(election.ID, candidateResult.CandidateID, threshold)
+			marker, err := ctx.GetStub().GetState(markerKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read from world state: %v", err)
+			}
+			if marker != nil {
+				continue
+			}
+
+			crossing := ThresholdCrossing{
+				ElectionID:  election.ID,
+				CandidateID: candidateResult.CandidateID,
+				Threshold:   threshold,
+				Percentage:  candidateResult.Percentage,
+			}
+			crossingJSON, err := json.Marshal(crossing)
+			if err != nil {
+				return nil, err
+			}
+			err = ctx.GetStub().PutState(markerKey, crossingJSON)
+			if err != nil {
+				return nil, err
+			}
+			crossings = append(crossings, crossing)
+		}
+	}
+
+	if len(crossings) > 0 {
+		eventJSON, err := json.Marshal(crossings)
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().SetEvent(candidateThresholdEvent, eventJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return crossings, nil
+}
+
+// CheckShareThresholds tallies an active or ended election and reports the
+// candidates that crossed one of its vote-share alert thresholds since the
+// last check, emitting a CandidateThreshold event for them. Elections hiding
+// their live results can only be checked once ended. Admin only
+func (s *VotingContract) CheckShareThresholds(ctx contractapi.TransactionContextInterface, electionID string) ([]ThresholdCrossing, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	if election.Status == "created" {
+		return nil, fmt.Errorf("election %s has not started yet", electionID)
+	}
+	err = checkLiveResultsVisible(election)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.tallyElection(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.recordThresholdCrossings(ctx, election, result)
+}
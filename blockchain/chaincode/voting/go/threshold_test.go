@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// events drains the chaincode events emitted so far
+func (e *testEnv) events() map[string][]byte {
+	events := make(map[string][]byte)
+	for {
+		select {
+		case event := <-e.stub.ChaincodeEventsChannel:
+			events[event.EventName] = event.Payload
+		default:
+			return events
+		}
+	}
+}
+
+func TestCheckShareThresholds(t *testing.T) {
+	type crossing struct {
+		candidateID string
+		threshold   float64
+	}
+	tests := []struct {
+		name  string
+		votes map[string]string // Voter to candidate, cast before the check
+		want  []crossing
+	}{
+		{"no votes yet", nil, nil},
+		{"first vote crosses both thresholds", map[string]string{"V1": "C1"}, []crossing{{"C1", 25}, {"C1", 50}}},
+		{"repeated check reports nothing", nil, nil},
+		{"second candidate overtakes", map[string]string{"V2": "C2", "V3": "C2"}, []crossing{{"C2", 25}, {"C2", 50}}},
+		{"falling back and rising again reports nothing", map[string]string{"V4": "C1", "V5": "C1"}, nil},
+	}
+
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5")
+	env.createElection("E1", "C1", "C2")
+	env.configure("E1", `{"shareAlertThresholds":[50,25]}`)
+	env.setStatus("E1", "active")
+
+	// The steps share one ledger and run in order
+	for _, tt := range tests {
+		for voterID, candidateID := range tt.votes {
+			env.mustVote("E1", voterID, candidateID)
+		}
+		env.events()
+
+		crossings, err := env.contract.CheckShareThresholds(env.ctx, "E1")
+		must(t, err)
+		if len(crossings) != len(tt.want) {
+			t.Fatalf("%s: got %d crossings %+v, want %d", tt.name, len(crossings), crossings, len(tt.want))
+		}
+		for i, got := range crossings {
+			if got.ElectionID != "E1" || got.CandidateID != tt.want[i].candidateID || got.Threshold != tt.want[i].threshold {
+				t.Errorf("%s: crossing %d = %+v, want %s at %v", tt.name, i, got, tt.want[i].candidateID, tt.want[i].threshold)
+			}
+		}
+
+		payload, emitted := env.events()[candidateThresholdEvent]
+		if emitted != (len(tt.want) > 0) {
+			t.Fatalf("%s: event emitted = %v, want %v", tt.name, emitted, len(tt.want) > 0)
+		}
+		if emitted {
+			var reported []ThresholdCrossing
+			must(t, json.Unmarshal(payload, &reported))
+			if len(reported) != len(crossings) {
+				t.Errorf("%s: event reports %d crossings, want %d", tt.name, len(reported), len(crossings))
+			}
+		}
+	}
+}
+
+func TestCheckShareThresholdsRefused(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		status  string
+		as      func(env *testEnv)
+		want    string
+	}{
+		{"non-admin", `{}`, "active", func(env *testEnv) { env.asUser() }, "access denied"},
+		{"election not started", `{}`, "created", func(env *testEnv) {}, "has not started yet"},
+		{"live results hidden", `{"hideLiveResults":true}`, "active", func(env *testEnv) {}, "hidden until it ends"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.createElection("E1", "C1")
+			env.configure("E1", tt.options)
+			if tt.status != "created" {
+				env.setStatus("E1", tt.status)
+			}
+			tt.as(env)
+
+			_, err := env.contract.CheckShareThresholds(env.ctx, "E1")
+			mustFail(t, err, tt.want)
+		})
+	}
+
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.createElection("E1", "C1")
+	mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", `{"shareAlertThresholds":[101]}`), "shareAlertThreshold")
+}
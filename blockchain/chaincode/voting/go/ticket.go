@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SetRunningMate pairs a candidate with a running mate on a ticket. The
+// running mate must be another registered candidate who neither heads a
+// ticket nor already runs on one, and the candidate must not be a running
+// mate themselves. An empty runningMateID removes the pairing. The ticket is
+// fixed once an election listing the candidate has started or been sealed.
+// Callable by the candidate or an admin
+func (s *VotingContract) SetRunningMate(ctx contractapi.TransactionContextInterface, candidateID string, runningMateID string) error {
+	if requireAdmin(ctx) != nil {
+		err := requireCandidate(ctx, candidateID)
+		if err != nil {
+			return err
+		}
+	}
+
+	candidate, err := s.GetCandidate(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkTicketChangeable(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+
+	if runningMateID != "" {
+		if runningMateID == candidateID {
+			return fmt.Errorf("candidate %s cannot be their own running mate", candidateID)
+		}
+		runningMate, err := s.GetCandidate(ctx, runningMateID)
+		if err != nil {
+			return err
+		}
+		if runningMate.RunningMateID != "" {
+			return fmt.Errorf("candidate %s heads a ticket with %s and cannot be a running mate", runningMateID, runningMate.RunningMateID)
+		}
+
+		candidates, err := s.GetAllCandidates(ctx)
+		if err != nil {
+			return err
+		}
+		for _, other := range candidates {
+			if other.ID == candidateID {
+				continue
+			}
+			if other.RunningMateID == candidateID {
+				return fmt.Errorf("candidate %s is the running mate of %s and cannot head a ticket", candidateID, other.ID)
+			}
+			if other.RunningMateID == runningMateID {
+				return fmt.Errorf("candidate %s is already the running mate of %s", runningMateID, other.ID)
+			}
+		}
+	}
+
+	candidate.RunningMateID = runningMateID
+	return s.putCandidate(ctx, candidate)
+}
+
+// checkTicketChangeable returns an error when an election listing the
+// candidate is active, ended or sealed, as its named results would change
+func (s *VotingContract) checkTicketChangeable(ctx contractapi.TransactionContextInterface, candidateID string) error {
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	for _, election := range elections {
+		if !containsString(election.Candidates, candidateID) {
+			continue
+		}
+		if election.Status != "created" {
+			return fmt.Errorf("the ticket of candidate %s cannot change while election %s is %s", candidateID, election.ID, election.Status)
+		}
+		seal, err := s.getCandidateListSeal(ctx, election.ID)
+		if err != nil {
+			return err
+		}
+		if seal != nil {
+			return fmt.Errorf("the ticket of candidate %s cannot change as election %s is sealed", candidateID, election.ID)
+		}
+	}
+
+	return nil
+}
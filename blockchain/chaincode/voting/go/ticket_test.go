@@ -0,0 +1,133 @@
+package main
+
+import "testing"
+
+func TestSetRunningMate(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  [][2]string // Tickets set beforehand, candidate then running mate
+		candidate string
+		mate      string
+		want      string
+	}{
+		{"valid ticket", nil, "C1", "C2", ""},
+		{"removing the running mate", [][2]string{{"C1", "C2"}}, "C1", "", ""},
+		{"changing the running mate", [][2]string{{"C1", "C2"}}, "C1", "C3", ""},
+		{"own running mate", nil, "C1", "C1", "cannot be their own running mate"},
+		{"unknown candidate", nil, "C9", "C2", "does not exist"},
+		{"unknown running mate", nil, "C1", "C9", "does not exist"},
+		{"running mate heads a ticket", [][2]string{{"C2", "C3"}}, "C1", "C2", "heads a ticket with C3"},
+		{"candidate is a running mate", [][2]string{{"C3", "C1"}}, "C1", "C2", "is the running mate of C3"},
+		{"running mate already taken", [][2]string{{"C3", "C2"}}, "C1", "C2", "already the running mate of C3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			for _, ticket := range tt.existing {
+				must(t, env.contract.SetRunningMate(env.ctx, ticket[0], ticket[1]))
+			}
+
+			err := env.contract.SetRunningMate(env.ctx, tt.candidate, tt.mate)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			candidate, err := env.contract.GetCandidate(env.ctx, tt.candidate)
+			must(t, err)
+			if candidate.RunningMateID != tt.mate {
+				t.Errorf("running mate = %q, want %q", candidate.RunningMateID, tt.mate)
+			}
+		})
+	}
+}
+
+func TestSetRunningMateAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller func(env *testEnv)
+		want   string
+	}{
+		{"admin", func(env *testEnv) { env.asAdmin() }, ""},
+		{"the candidate", func(env *testEnv) { env.asCandidate("C1") }, ""},
+		{"another candidate", func(env *testEnv) { env.asCandidate("C2") }, "access denied"},
+		{"ordinary client", func(env *testEnv) { env.asUser() }, "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			tt.caller(env)
+			err := env.contract.SetRunningMate(env.ctx, "C1", "C2")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+		})
+	}
+}
+
+func TestRunningMateFixedOnceListed(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(env *testEnv)
+		want    string
+	}{
+		{"created election", func(env *testEnv) {}, ""},
+		{"active election", func(env *testEnv) { env.setStatus("E1", "active") }, "cannot change while election E1 is active"},
+		{"ended election", func(env *testEnv) { env.setStatus("E1", "ended") }, "cannot change while election E1 is ended"},
+		{"sealed candidate list", func(env *testEnv) {
+			must(env.t, env.contract.SealElection(env.ctx, "E1"))
+		}, "cannot change as election E1 is sealed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.createElection("E1", "C1", "C3")
+			tt.prepare(env)
+
+			err := env.contract.SetRunningMate(env.ctx, "C1", "C2")
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+		})
+	}
+
+	// Candidates not listed in a running election keep their ticket open
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3")
+	env.createElection("E1", "C3")
+	env.setStatus("E1", "active")
+	must(t, env.contract.SetRunningMate(env.ctx, "C1", "C2"))
+}
+
+func TestRunningMateInNamedResults(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3")
+	env.registerVoters("North", "V1")
+	must(t, env.contract.SetRunningMate(env.ctx, "C1", "C3"))
+	env.createElection("E1", "C1", "C2")
+	env.setStatus("E1", "active")
+	env.mustVote("E1", "V1", "C1")
+	env.endElection("E1")
+
+	result, err := env.contract.GetElectionResultsNamed(env.ctx, "E1")
+	must(t, err)
+	for _, candidateResult := range result.CandidateResults {
+		want := ""
+		if candidateResult.CandidateID == "C1" {
+			want = "C3"
+		}
+		if candidateResult.RunningMateID != want {
+			t.Errorf("running mate of %s = %q, want %q", candidateResult.CandidateID, candidateResult.RunningMateID, want)
+		}
+	}
+}
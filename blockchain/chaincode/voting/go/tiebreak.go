@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TieBreak records how a tie for first place was resolved by lot
+type TieBreak struct {
+	ElectionID     string    `json:"electionId"`
+	Seed           string    `json:"seed"`
+	TiedCandidates []string  `json:"tiedCandidates"` // Sorted
+	Chosen         string    `json:"chosen"`
+	ResolvedAt     time.Time `json:"resolvedAt"`
+}
+
+// drawByLot deterministically picks one of the sorted candidates from a
+// hash of the election ID, the seed and the candidate IDs
+func drawByLot(electionID string, seed string, sortedCandidates []string) string {
+	hash := sha256.Sum256([]byte(electionID + "|" + seed + "|" + strings.Join(sortedCandidates, ",")))
+	index := binary.BigEndian.Uint64(hash[:8]) % uint64(len(sortedCandidates))
+	return sortedCandidates[index]
+}
+
+// ResolveTieByLot resolves a tie for first place in an ended election by a
+// reproducible draw and records the outcome. Calling it again with the same
+// seed returns the recorded outcome. Admin only
+func (s *VotingContract) ResolveTieByLot(ctx contractapi.TransactionContextInterface, electionID string, seed string) (string, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return "", err
+	}
+	if seed == "" {
+		return "", fmt.Errorf("seed must not be empty")
+	}
+
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return "", err
+	}
+
+	tieBreakJSON, err := ctx.GetStub().GetState("TIEBREAK_" + electionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if tieBreakJSON != nil {
+		var existing TieBreak
+		err = json.Unmarshal(tieBreakJSON, &existing)
+		if err != nil {
+			return "", err
+		}
+		if existing.Seed != seed {
+			return "", fmt.Errorf("the tie in election %s was already resolved with a different seed", electionID)
+		}
+		return existing.Chosen, nil
+	}
+
+	topVotes := -1
+	var tied []string
+	for _, candidateResult := range standingResults(election, result) {
+		switch {
+		case candidateResult.VoteCount > topVotes:
+			topVotes = candidateResult.VoteCount
+			tied = []string{candidateResult.CandidateID}
+		case candidateResult.VoteCount == topVotes:
+			tied = append(tied, candidateResult.CandidateID)
+		}
+	}
+	if len(tied) < 2 {
+		return "", fmt.Errorf("election %s has no tie for first place", electionID)
+	}
+	err = checkNotCertified(election)
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(tied)
+
+	resolvedAt, err := getTxTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	tieBreak := TieBreak{
+		ElectionID:     electionID,
+		Seed:           seed,
+		TiedCandidates: tied,
+		Chosen:         drawByLot(electionID, seed, tied),
+		ResolvedAt:     resolvedAt,
+	}
+	tieBreakJSON, err = json.Marshal(tieBreak)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState("TIEBREAK_"+electionID, tieBreakJSON)
+	if err != nil {
+		return "", err
+	}
+
+	return tieBreak.Chosen, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDrawByLot(t *testing.T) {
+	candidates := []string{"C1", "C2", "C3"}
+	first := drawByLot("E1", "seed", candidates)
+	if !containsString(candidates, first) {
+		t.Fatalf("drew %s, which is not among %v", first, candidates)
+	}
+	for i := 0; i < 5; i++ {
+		if got := drawByLot("E1", "seed", candidates); got != first {
+			t.Fatalf("draw is not deterministic: got %s then %s", first, got)
+		}
+	}
+
+	// Different seeds must be able to pick different candidates
+	drawn := make(map[string]bool)
+	for _, seed := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		drawn[drawByLot("E1", seed, candidates)] = true
+	}
+	if len(drawn) < 2 {
+		t.Errorf("eight seeds all drew %v", drawn)
+	}
+}
+
+func TestResolveTieByLot(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes map[string]string // Voter ID to candidate ID
+		seed  string
+		want  string
+	}{
+		{"tie for first place", map[string]string{"V1": "C1", "V2": "C2"}, "seed", ""},
+		{"no tie", map[string]string{"V1": "C1", "V2": "C1", "V3": "C2"}, "seed", "no tie for first place"},
+		{"empty seed", map[string]string{"V1": "C1", "V2": "C2"}, "", "seed must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			for voterID, candidateID := range tt.votes {
+				env.mustVote("E1", voterID, candidateID)
+			}
+			env.endElection("E1")
+
+			chosen, err := env.contract.ResolveTieByLot(env.ctx, "E1", tt.seed)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			if chosen != drawByLot("E1", tt.seed, []string{"C1", "C2"}) {
+				t.Errorf("chose %s, not the draw over the tied candidates", chosen)
+			}
+
+			// The recorded outcome is returned again for the same seed only
+			again, err := env.contract.ResolveTieByLot(env.ctx, "E1", tt.seed)
+			must(t, err)
+			if again != chosen {
+				t.Errorf("second call chose %s, want the recorded %s", again, chosen)
+			}
+			_, err = env.contract.ResolveTieByLot(env.ctx, "E1", tt.seed+"-other")
+			mustFail(t, err, "already resolved with a different seed")
+
+			env.asUser()
+			_, err = env.contract.ResolveTieByLot(env.ctx, "E1", tt.seed)
+			mustFail(t, err, "access denied")
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// LeadChange records a point at which a different candidate took the lead
+type LeadChange struct {
+	CandidateID      string    `json:"candidateId"`
+	PreviousLeaderID string    `json:"previousLeaderId"` // Empty for the first leader
+	Timestamp        time.Time `json:"timestamp"`
+	VoteCount        int       `json:"voteCount"`    // New leader's votes at that point
+	VotesCounted     int       `json:"votesCounted"` // Candidate votes counted up to that point
+}
+
+// countedVotesInOrder returns the votes of an election that count towards its
+// tally, oldest first. Ties in time are broken by transaction and voter ID
+func countedVotesInOrder(election *Election, votes []*Vote) []*Vote {
+	counted := []*Vote{}
+	for _, vote := range votes {
+		if !isCountedVote(election, vote) {
+			continue
+		}
+		counted = append(counted, vote)
+	}
+	sort.SliceStable(counted, func(i, j int) bool {
+		if !counted[i].Timestamp.Equal(counted[j].Timestamp) {
+			return counted[i].Timestamp.Before(counted[j].Timestamp)
+		}
+		if counted[i].TxID != counted[j].TxID {
+			return counted[i].TxID < counted[j].TxID
+		}
+		return counted[i].VoterID < counted[j].VoterID
+	})
+	return counted
+}
+
+// getEndedElectionVotes returns an ended election whose results may be
+// released, together with its counted votes in time order
+func (s *VotingContract) getEndedElectionVotes(ctx contractapi.TransactionContextInterface, electionID string) (*Election, []*Vote, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if election.Status != "ended" {
+		return nil, nil, &ElectionNotEndedError{ElectionID: electionID, Status: election.Status}
+	}
+	err = checkResultsReleasable(ctx, election)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return election, countedVotesInOrder(election, votes), nil
+}
+
+// GetLeadChanges replays the candidate votes of an ended election in time
+// order and returns each point at which the leading candidate changed,
+// starting with the first leader. A tie does not unseat the current leader
+func (s *VotingContract) GetLeadChanges(ctx contractapi.TransactionContextInterface, electionID string) ([]LeadChange, error) {
+	_, votes, err := s.getEndedElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []LeadChange{}
+	counts := make(map[string]int)
+	leader := ""
+	counted := 0
+	for _, vote := range votes {
+		if isReservedSelection(vote.CandidateID) {
+			continue
+		}
+		counts[vote.CandidateID]++
+		counted++
+
+		if vote.CandidateID == leader || counts[vote.CandidateID] <= counts[leader] {
+			continue
+		}
+		changes = append(changes, LeadChange{
+			CandidateID:      vote.CandidateID,
+			PreviousLeaderID: leader,
+			Timestamp:        vote.Timestamp,
+			VoteCount:        counts[vote.CandidateID],
+			VotesCounted:     counted,
+		})
+		leader = vote.CandidateID
+	}
+
+	return changes, nil
+}
+
+// GetTurnoutTimeSeries returns the timestamps of every vote cast in an
+// election, oldest first, without any voter or candidate information
+func (s *VotingContract) GetTurnoutTimeSeries(ctx contractapi.TransactionContextInterface, electionID string) ([]time.Time, error) {
+	_, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.getElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps := make([]time.Time, 0, len(votes))
+	for _, vote := range votes {
+		timestamps = append(timestamps, vote.Timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
+
+	return timestamps, nil
+}
+
+// GetCandidateVoteTimeline returns the timestamps of the counted votes for a
+// candidate, oldest first. To protect ballot secrecy it is only available
+// once the election has ended and its results may be released
+func (s *VotingContract) GetCandidateVoteTimeline(ctx contractapi.TransactionContextInterface, electionID string, candidateID string) ([]time.Time, error) {
+	election, votes, err := s.getEndedElectionVotes(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(election.Candidates, candidateID) {
+		return nil, fmt.Errorf("candidate %s is not part of election %s", candidateID, electionID)
+	}
+
+	timestamps := []time.Time{}
+	for _, vote := range votes {
+		if vote.CandidateID == candidateID {
+			timestamps = append(timestamps, vote.Timestamp)
+		}
+	}
+
+	return timestamps, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetLeadChanges(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5", "V6", "V7")
+	env.createElection("E1", "C1", "C2")
+	env.configure("E1", `{"allowNota":true}`)
+	env.setStatus("E1", "active")
+
+	// Voters vote in reverse key order, so time order differs from ledger order.
+	// A tie at the second vote leaves C1 ahead; NOTA counts for nobody
+	sequence := []struct {
+		voterID     string
+		candidateID string
+	}{
+		{"V7", "C1"},
+		{"V6", "C2"},
+		{"V5", SelectionNOTA},
+		{"V4", "C2"},
+		{"V3", "C1"},
+		{"V2", "C1"},
+		{"V1", "C2"},
+	}
+	start := env.now
+	for _, vote := range sequence {
+		env.advance(time.Minute)
+		env.mustVote("E1", vote.voterID, vote.candidateID)
+	}
+
+	_, err := env.contract.GetLeadChanges(env.ctx, "E1")
+	var notEnded *ElectionNotEndedError
+	if !errors.As(err, &notEnded) {
+		t.Fatalf("lead changes of an active election: got %v, want ElectionNotEndedError", err)
+	}
+	env.endElection("E1")
+
+	changes, err := env.contract.GetLeadChanges(env.ctx, "E1")
+	must(t, err)
+	want := []LeadChange{
+		{CandidateID: "C1", PreviousLeaderID: "", Timestamp: start.Add(time.Minute), VoteCount: 1, VotesCounted: 1},
+		{CandidateID: "C2", PreviousLeaderID: "C1", Timestamp: start.Add(4 * time.Minute), VoteCount: 2, VotesCounted: 3},
+		{CandidateID: "C1", PreviousLeaderID: "C2", Timestamp: start.Add(6 * time.Minute), VoteCount: 3, VotesCounted: 5},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d lead changes %+v, want %d", len(changes), changes, len(want))
+	}
+	for i, change := range changes {
+		if change.CandidateID != want[i].CandidateID || change.PreviousLeaderID != want[i].PreviousLeaderID ||
+			!change.Timestamp.Equal(want[i].Timestamp) || change.VoteCount != want[i].VoteCount || change.VotesCounted != want[i].VotesCounted {
+			t.Errorf("change %d = %+v, want %+v", i, change, want[i])
+		}
+	}
+}
+
+func TestGetLeadChangesWithoutVotes(t *testing.T) {
+	env := setupElection(t)
+	env.endElection("E1")
+
+	changes, err := env.contract.GetLeadChanges(env.ctx, "E1")
+	must(t, err)
+	if len(changes) != 0 {
+		t.Errorf("lead changes without votes = %+v, want none", changes)
+	}
+}
+
+func TestGetTurnoutTimeSeries(t *testing.T) {
+	tests := []struct {
+		name    string
+		offsets map[string]time.Duration // Voter to vote time after the start
+	}{
+		{"no votes", nil},
+		{"votes out of key order", map[string]time.Duration{"V1": 30 * time.Minute, "V2": 5 * time.Minute, "V3": 50 * time.Minute, "V4": 20 * time.Minute}},
+		{"votes at the same time", map[string]time.Duration{"V1": time.Minute, "V2": time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			start := env.now
+			for voterID, offset := range tt.offsets {
+				env.setTime(start.Add(offset))
+				env.mustVote("E1", voterID, "C1")
+			}
+
+			timestamps, err := env.contract.GetTurnoutTimeSeries(env.ctx, "E1")
+			must(t, err)
+			if len(timestamps) != len(tt.offsets) {
+				t.Fatalf("got %d timestamps, want %d", len(timestamps), len(tt.offsets))
+			}
+			seen := make(map[time.Duration]int)
+			for _, offset := range tt.offsets {
+				seen[offset]++
+			}
+			for i, timestamp := range timestamps {
+				if i > 0 && timestamp.Before(timestamps[i-1]) {
+					t.Errorf("timestamp %d is older than the one before it", i)
+				}
+				seen[timestamp.Sub(start)]--
+			}
+			for offset, count := range seen {
+				if count != 0 {
+					t.Errorf("vote time %v is missing or repeated", offset)
+				}
+			}
+		})
+	}
+
+	env := newTestEnv(t)
+	_, err := env.contract.GetTurnoutTimeSeries(env.ctx, "MISSING")
+	mustFail(t, err, "does not exist")
+}
+
+func TestGetCandidateVoteTimeline(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2", "C3", "C9")
+	env.registerVoters("North", "V1", "V2", "V3", "V4", "V5")
+	env.createElection("E1", "C1", "C2", "C3")
+	env.setStatus("E1", "active")
+	start := env.now
+	// Offsets in minutes, out of key order
+	sequence := []struct {
+		voterID     string
+		candidateID string
+		offset      time.Duration
+	}{
+		{"V1", "C1", 40},
+		{"V2", "C2", 10},
+		{"V3", "C1", 5},
+		{"V4", "C1", 20},
+		{"V5", "C2", 30},
+	}
+	for _, vote := range sequence {
+		env.setTime(start.Add(vote.offset * time.Minute))
+		env.mustVote("E1", vote.voterID, vote.candidateID)
+	}
+
+	_, err := env.contract.GetCandidateVoteTimeline(env.ctx, "E1", "C1")
+	var notEnded *ElectionNotEndedError
+	if !errors.As(err, &notEnded) {
+		t.Fatalf("timeline of an active election: got %v, want ElectionNotEndedError", err)
+	}
+	env.endElection("E1")
+	tally := candidateVotes(env.results("E1"))
+
+	tests := []struct {
+		candidateID string
+		want        []time.Duration
+		wantErr     string
+	}{
+		{"C1", []time.Duration{5, 20, 40}, ""},
+		{"C2", []time.Duration{10, 30}, ""},
+		{"C3", []time.Duration{}, ""},
+		{"C9", nil, "candidate C9 is not part of election E1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.candidateID, func(t *testing.T) {
+			timeline, err := env.contract.GetCandidateVoteTimeline(env.ctx, "E1", tt.candidateID)
+			if tt.wantErr != "" {
+				mustFail(t, err, tt.wantErr)
+				return
+			}
+			must(t, err)
+			if len(timeline) != tally[tt.candidateID] {
+				t.Errorf("timeline has %d entries, but the tally counts %d votes", len(timeline), tally[tt.candidateID])
+			}
+			if len(timeline) != len(tt.want) {
+				t.Fatalf("timeline = %v, want %d entries", timeline, len(tt.want))
+			}
+			for i, timestamp := range timeline {
+				if !timestamp.Equal(start.Add(tt.want[i] * time.Minute)) {
+					t.Errorf("entry %d = %v, want %v", i, timestamp, start.Add(tt.want[i]*time.Minute))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxNumericInput caps integer inputs such as weights, seat counts and page
+// sizes so that absurd values cannot overflow downstream arithmetic
+const maxNumericInput = 1000000000
+
+// validatePositiveInt checks that an integer input (weight, page size, seat
+// count, ...) is at least 1 and not absurdly large
+func validatePositiveInt(field string, value int) error {
+	if value < 1 {
+		return fmt.Errorf("invalid %s: %d. Value must be at least 1", field, value)
+	}
+	if value > maxNumericInput {
+		return fmt.Errorf("invalid %s: %d. Value must not exceed %d", field, value, maxNumericInput)
+	}
+	return nil
+}
+
+// validateNonNegativeInt checks that an integer input is zero or greater and
+// not absurdly large. Zero is typically used to mean "no limit"
+func validateNonNegativeInt(field string, value int) error {
+	if value < 0 {
+		return fmt.Errorf("invalid %s: %d. Value must not be negative", field, value)
+	}
+	if value > maxNumericInput {
+		return fmt.Errorf("invalid %s: %d. Value must not exceed %d", field, value, maxNumericInput)
+	}
+	return nil
+}
+
+// validatePercentage checks that a percentage input lies within [0,100]
+func validatePercentage(field string, value float64) error {
+	if math.IsNaN(value) || value < 0 || value > 100 {
+		return fmt.Errorf("invalid %s: %v. Percentage must be between 0 and 100", field, value)
+	}
+	return nil
+}
+
+// validateFraction checks that a fractional input lies within [0,1]
+func validateFraction(field string, value float64) error {
+	if math.IsNaN(value) || value < 0 || value > 1 {
+		return fmt.Errorf("invalid %s: %v. Fraction must be between 0 and 1", field, value)
+	}
+	return nil
+}
+
+// Limits on election metadata entries
+const (
+	maxMetadataKeyLength   = 64
+	maxMetadataValueLength = 1024
+	maxMetadataEntries     = 32
+)
+
+// validateMetadataEntry checks the length limits of an election metadata entry
+func validateMetadataEntry(key string, value string) error {
+	if key == "" {
+		return fmt.Errorf("metadata key must not be empty")
+	}
+	if len(key) > maxMetadataKeyLength {
+		return fmt.Errorf("metadata key %s exceeds %d characters", key, maxMetadataKeyLength)
+	}
+	if len(value) > maxMetadataValueLength {
+		return fmt.Errorf("metadata value for %s exceeds %d characters", key, maxMetadataValueLength)
+	}
+	return nil
+}
+
+// normalizeElectionStatus trims and lowercases a status so that variants
+// like " Active " are accepted, then checks it is a known election status
+func normalizeElectionStatus(status string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(status))
+	if normalized != "created" && normalized != "active" && normalized != "ended" {
+		return "", fmt.Errorf("invalid status: %s. Status must be 'created', 'active', or 'ended'", status)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidatePositiveInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   int
+		wantErr bool
+	}{
+		{"one", 1, false},
+		{"maximum", maxNumericInput, false},
+		{"zero", 0, true},
+		{"negative", -5, true},
+		{"above maximum", maxNumericInput + 1, true},
+		{"overflowing", math.MaxInt32, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePositiveInt("weight", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validatePositiveInt(%d) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNonNegativeInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   int
+		wantErr bool
+	}{
+		{"zero", 0, false},
+		{"positive", 42, false},
+		{"negative", -1, true},
+		{"above maximum", maxNumericInput + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNonNegativeInt("pageSize", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateNonNegativeInt(%d) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePercentage(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		wantErr bool
+	}{
+		{"zero", 0, false},
+		{"fractional", 33.3, false},
+		{"hundred", 100, false},
+		{"negative", -0.1, true},
+		{"above hundred", 100.5, true},
+		{"not a number", math.NaN(), true},
+		{"infinite", math.Inf(1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePercentage("quorumPercent", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validatePercentage(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFraction(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		wantErr bool
+	}{
+		{"zero", 0, false},
+		{"one", 1, false},
+		{"sixth", 1.0 / 6, false},
+		{"negative", -0.5, true},
+		{"above one", 1.5, true},
+		{"not a number", math.NaN(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFraction("depositForfeitureFraction", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateFraction(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigureElectionRejectsOutOfRangeNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{"zero seats", `{"seatsPerConstituency":0}`, "invalid seatsPerConstituency"},
+		{"negative grace period", `{"gracePeriodSeconds":-10}`, "invalid gracePeriodSeconds"},
+		{"huge capacity", `{"maxTotalVotes":2000000000}`, "must not exceed"},
+		{"negative quorum", `{"quorumPercent":-1}`, "invalid quorumPercent"},
+		{"quorum above hundred", `{"quorumPercent":150}`, "invalid quorumPercent"},
+		{"alert threshold above hundred", `{"shareAlertThresholds":[50,101]}`, "invalid shareAlertThreshold"},
+		{"negative phase", `{"constituencyPhases":{"North":-1}}`, "invalid phase for constituency North"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.createElection("E1")
+			mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", tt.options), tt.want)
+		})
+	}
+}
+
+func TestSetConfigRejectsOutOfRangeNumbers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"negative cooldown", `{"voteCooldownSeconds":-30}`, "invalid voteCooldownSeconds"},
+		{"negative candidate cap", `{"maxCandidatesPerConstituency":-2}`, "invalid maxCandidatesPerConstituency"},
+		{"forfeiture fraction above one", `{"depositForfeitureFraction":2}`, "invalid depositForfeitureFraction"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			mustFail(t, env.contract.SetConfig(env.ctx, tt.config), tt.want)
+		})
+	}
+}
+
+func TestNormalizeElectionStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		want    string
+		wantErr bool
+	}{
+		{"active", "active", false},
+		{" Active ", "active", false},
+		{"ENDED", "ended", false},
+		{"\tcreated\n", "created", false},
+		{"", "", true},
+		{"paused", "", true},
+		{"act ive", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got, err := normalizeElectionStatus(tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeElectionStatus(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeElectionStatus(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateElectionStatusNormalizes(t *testing.T) {
+	env := newTestEnv(t)
+	env.createElection("E1")
+
+	env.setStatus("E1", " ACTIVE ")
+	if status := env.election("E1").Status; status != "active" {
+		t.Errorf("stored status = %q, want active", status)
+	}
+	mustFail(t, env.contract.UpdateElectionStatus(env.ctx, "E1", "closed"), "invalid status")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ValidityReport is the consolidated verdict on whether an election result is valid
+type ValidityReport struct {
+	ElectionID           string   `json:"electionId"`
+	Valid                bool     `json:"valid"`
+	QuorumMet            bool     `json:"quorumMet"`     // Always true without a QuorumPercent
+	MarginMet            bool     `json:"marginMet"`     // Always true without a MinWinningMargin and for referendums
+	CandidatesMet        bool     `json:"candidatesMet"` // Always true without MinCandidates and for referendums
+	TurnoutPercent       float64  `json:"turnoutPercent"`
+	QuorumTurnoutPercent float64  `json:"quorumTurnoutPercent"` // Turnout without abstentions, which the quorum is checked against
+	Failures             []string `json:"failures"`             // One entry per condition not met
+}
+
+// GetElectionValidity checks an ended election against its quorum, minimum
+// winning margin and minimum candidate conditions, and reports which failed.
+// Abstentions count towards turnout but not towards the quorum
+func (s *VotingContract) GetElectionValidity(ctx contractapi.TransactionContextInterface, electionID string) (*ValidityReport, error) {
+	election, result, err := s.getEndedElectionResults(ctx, electionID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := ValidityReport{
+		ElectionID:    electionID,
+		QuorumMet:     true,
+		MarginMet:     true,
+		CandidatesMet: true,
+		Failures:      []string{},
+	}
+
+	turnout, err := s.electionTurnout(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+	report.TurnoutPercent = turnout.TurnoutPercent
+	if turnout.EligibleVoters > 0 {
+		share := float64(turnout.VotesCast-result.AbstainVotes) * 100 / float64(turnout.EligibleVoters)
+		report.QuorumTurnoutPercent = math.Round(share*100) / 100
+	}
+	if report.QuorumTurnoutPercent < election.QuorumPercent {
+		report.QuorumMet = false
+		report.Failures = append(report.Failures, fmt.Sprintf("turnout of %.2f%% excluding abstentions is below the quorum of %.2f%%", report.QuorumTurnoutPercent, election.QuorumPercent))
+	}
+
+	if election.Type != ElectionTypeReferendum {
+		standing := 0
+		for _, candidateID := range election.Candidates {
+			if !isWithdrawn(election, candidateID) {
+				standing++
+			}
+		}
+		if standing < election.MinCandidates {
+			report.CandidatesMet = false
+			report.Failures = append(report.Failures, fmt.Sprintf("%d candidates stood, fewer than the minimum of %d", standing, election.MinCandidates))
+		}
+
+		if election.MinWinningMargin > 0 && standing > 0 {
+			winner, err := s.GetElectionWinner(ctx, electionID)
+			if err != nil {
+				return nil, err
+			}
+			if winner.IsProvisional {
+				report.MarginMet = false
+				report.Failures = append(report.Failures, fmt.Sprintf("winning margin of %d votes is below the minimum of %d", winner.Margin, election.MinWinningMargin))
+			}
+		}
+	}
+
+	report.Valid = len(report.Failures) == 0
+
+	return &report, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGetElectionValidity(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       string
+		selections    []string
+		withdraw      []string
+		wantQuorum    bool
+		wantMargin    bool
+		wantCandidate bool
+	}{
+		{"no conditions", `{}`, []string{"C1"}, nil, true, true, true},
+		{"quorum met", `{"quorumPercent":75}`, []string{"C1", "C1", "C2"}, nil, true, true, true},
+		{"quorum missed", `{"quorumPercent":75}`, []string{"C1", "C2"}, nil, false, true, true},
+		{"margin met", `{"minWinningMargin":2}`, []string{"C1", "C1", "C1", "C2"}, nil, true, true, true},
+		{"margin missed", `{"minWinningMargin":2}`, []string{"C1", "C1", "C2"}, nil, true, false, true},
+		{"enough candidates", `{"minCandidates":3}`, []string{"C1"}, nil, true, true, true},
+		{"withdrawal leaves too few candidates", `{"minCandidates":3}`, []string{"C1"}, []string{"C3"}, true, true, false},
+		{"every condition missed", `{"quorumPercent":100,"minWinningMargin":5,"minCandidates":3}`, []string{"C1", "C2"}, []string{"C3"}, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2", "C3")
+			env.registerVoters("North", "V1", "V2", "V3", "V4")
+			env.createElection("E1", "C1", "C2", "C3")
+			env.configure("E1", tt.options)
+			env.setStatus("E1", "active")
+			for i, candidateID := range tt.selections {
+				env.mustVote("E1", fmt.Sprintf("V%d", i+1), candidateID)
+			}
+			for _, candidateID := range tt.withdraw {
+				must(t, env.contract.WithdrawCandidate(env.ctx, "E1", candidateID))
+			}
+
+			_, err := env.contract.GetElectionValidity(env.ctx, "E1")
+			var notEnded *ElectionNotEndedError
+			if !errors.As(err, &notEnded) {
+				t.Fatalf("validity of an active election: got %v, want ElectionNotEndedError", err)
+			}
+			env.endElection("E1")
+
+			report, err := env.contract.GetElectionValidity(env.ctx, "E1")
+			must(t, err)
+			if report.QuorumMet != tt.wantQuorum || report.MarginMet != tt.wantMargin || report.CandidatesMet != tt.wantCandidate {
+				t.Errorf("quorum %v, margin %v, candidates %v; want %v, %v, %v", report.QuorumMet, report.MarginMet, report.CandidatesMet, tt.wantQuorum, tt.wantMargin, tt.wantCandidate)
+			}
+			failures := 0
+			for _, met := range []bool{tt.wantQuorum, tt.wantMargin, tt.wantCandidate} {
+				if !met {
+					failures++
+				}
+			}
+			if len(report.Failures) != failures || report.Valid != (failures == 0) {
+				t.Errorf("valid %v with failures %v, want %d failures", report.Valid, report.Failures, failures)
+			}
+			if want := float64(len(tt.selections)) * 25; report.TurnoutPercent != want {
+				t.Errorf("turnout = %v, want %v", report.TurnoutPercent, want)
+			}
+		})
+	}
+}
+
+func TestReferendumValidity(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerVoters("North", "V1", "V2", "V3", "V4")
+	env.createElection("R1")
+	env.configure("R1", `{"type":"referendum","quorumPercent":50,"minCandidates":2,"minWinningMargin":3}`)
+	env.setStatus("R1", "active")
+	env.mustVote("R1", "V1", SelectionYes)
+	env.mustVote("R1", "V2", SelectionAbstain)
+	env.endElection("R1")
+
+	// Candidate and margin conditions do not apply to referendums, and the
+	// abstention keeps the turnout below the quorum
+	report, err := env.contract.GetElectionValidity(env.ctx, "R1")
+	must(t, err)
+	if report.Valid || report.QuorumMet || !report.MarginMet || !report.CandidatesMet {
+		t.Errorf("report = %+v, want only the quorum to fail", report)
+	}
+	if report.TurnoutPercent != 50 || report.QuorumTurnoutPercent != 25 {
+		t.Errorf("turnout %v, quorum turnout %v; want 50, 25", report.TurnoutPercent, report.QuorumTurnoutPercent)
+	}
+}
+
+func TestValidityConditionsValidated(t *testing.T) {
+	tests := []struct {
+		options string
+		want    string
+	}{
+		{`{"quorumPercent":-1}`, "invalid quorumPercent"},
+		{`{"quorumPercent":101}`, "invalid quorumPercent"},
+		{`{"minCandidates":-1}`, "invalid minCandidates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.options, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.createElection("E1")
+			mustFail(t, env.contract.ConfigureElection(env.ctx, "E1", tt.options), tt.want)
+		})
+	}
+}
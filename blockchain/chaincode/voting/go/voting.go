@@ -2,8 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -13,31 +17,156 @@ type VotingContract struct {
 	contractapi.Contract
 }
 
+// reservedKeyPrefixes lists the world state key prefixes used for records
+// other than elections, which are stored under their bare ID
+var reservedKeyPrefixes = []string{
+	"CANDIDATE_",
+	"VOTER_",
+	"VOTE_",
+	"IDEMPOTENCY_",
+	"ROLL_",
+	"CHECKIN_",
+	"CONFIG_",
+	"RESULT_",
+	"ATTEMPT_",
+	"TIEBREAK_",
+	"OBSERVER_",
+	"NOMINATION_",
+	"SNAPSHOT_",
+	"THRESHOLD_",
+	"VOTECOUNT_",
+	"SEAL_",
+	"FEE_",
+	"DELEGATION_",
+	"CREDENTIAL_",
+}
+
+// isElectionKey returns true when the world state key holds an election
+func isElectionKey(key string) bool {
+	for _, prefix := range reservedKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateElectionID checks that an election ID is not empty and does not
+// start with a reserved prefix, which would make the election collide with
+// other records and disappear from election queries
+func validateElectionID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("election ID must not be empty")
+	}
+	for _, prefix := range reservedKeyPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return fmt.Errorf("invalid election ID %s: must not start with the reserved prefix %s", id, prefix)
+		}
+	}
+	return nil
+}
+
+// prefixRangeEnd returns the exclusive end key for a range scan over all
+// keys starting with prefix
+func prefixRangeEnd(prefix string) string {
+	return prefix + string(utf8.MaxRune)
+}
+
+// getTxTime returns the transaction timestamp, which unlike the local clock
+// is identical on every endorsing peer
+func getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read transaction timestamp: %v", err)
+	}
+	return time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC(), nil
+}
+
+// getTransientValue returns a field of the transaction's transient data, or
+// an empty string when it is absent. Transient data is passed to the
+// endorsing peers but never written to the ledger
+func getTransientValue(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transient data: %v", err)
+	}
+	return string(transientMap[key]), nil
+}
+
+// containsString returns true when value is present in values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // Election represents an election
 type Election struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	StartTime   time.Time `json:"startTime"`
-	EndTime     time.Time `json:"endTime"`
-	Status      string    `json:"status"` // "created", "active", "ended"
-	Candidates  []string  `json:"candidates"`
+	ID                          string               `json:"id"`
+	Name                        string               `json:"name"`
+	Description                 string               `json:"description"`
+	StartTime                   time.Time            `json:"startTime"`
+	EndTime                     time.Time            `json:"endTime"`
+	Status                      string               `json:"status"` // "created", "active", "ended"
+	Candidates                  []string             `json:"candidates"`
+	TestMode                    bool                 `json:"testMode"` // Test elections are hidden from default queries
+	AllowNOTA                   bool                 `json:"allowNota"`
+	AllowBlank                  bool                 `json:"allowBlank"`
+	AllowWriteIn                bool                 `json:"allowWriteIn"`
+	AllowSpoiled                bool                 `json:"allowSpoiled"` // Accept SPOILED selections recorded from paper ballots
+	AllowAbstain                bool                 `json:"allowAbstain"` // Accept deliberate ABSTAIN selections
+	WithdrawnCandidates         []string             `json:"withdrawnCandidates"`
+	CandidateSerials            map[string]int       `json:"candidateSerials"`           // Stable per-candidate serial numbers for tie-breaks
+	RequireCheckIn              bool                 `json:"requireCheckIn"`             // Only accept votes from voters checked in with CheckInVoter
+	SeatsPerConstituency        int                  `json:"seatsPerConstituency"`       // Winners per constituency, 0 meaning a single seat
+	ConstituencyEndTimes        map[string]time.Time `json:"constituencyEndTimes"`       // Staggered closing times of individual constituencies
+	AllConstituenciesMustClose  bool                 `json:"allConstituenciesMustClose"` // Withhold results until every constituency has closed
+	Constituencies              []string             `json:"constituencies"`             // Constituencies declared as covered, in addition to those of the candidates
+	Metadata                    map[string]string    `json:"metadata"`                   // Deployment-specific fields such as region codes
+	Version                     int                  `json:"version"`                    // Incremented on every write, for optimistic concurrency
+	LastModified                time.Time            `json:"lastModified"`
+	GracePeriodSeconds          int                  `json:"gracePeriodSeconds"`          // Votes arriving this long after EndTime are accepted as late
+	ExcludeLateVotes            bool                 `json:"excludeLateVotes"`            // Leave late votes out of the tally
+	WriteInMaxLength            int                  `json:"writeInMaxLength"`            // 0 uses the default limit
+	WriteInExtraChars           string               `json:"writeInExtraChars"`           // Characters allowed in write-in names beyond letters, spaces and .-'
+	Certified                   bool                 `json:"certified"`                   // Set by FinalizeResults; the election can no longer change
+	ShareAlertThresholds        []float64            `json:"shareAlertThresholds"`        // Vote-share percentages that raise a CandidateThreshold event during counting
+	MaxTotalVotes               int                  `json:"maxTotalVotes"`               // Cap on ballots cast in the election, 0 for no cap
+	CertifiedConstituencies     []string             `json:"certifiedConstituencies"`     // Constituencies whose count has been certified individually
+	ReceiptTemplate             string               `json:"receiptTemplate"`             // Text rendered by GetVoteReceipt, empty for the default
+	RequirePriorParticipationIn string               `json:"requirePriorParticipationIn"` // Election whose voters alone may vote, e.g. the first round of a runoff
+	Type                        string               `json:"type"`                        // ElectionTypeReferendum, or empty for a candidate election
+	MinWinningMargin            int                  `json:"minWinningMargin"`            // Lead over the runner-up below which the winner is provisional, 0 for none
+	QuorumPercent               float64              `json:"quorumPercent"`               // Turnout needed for a valid result, 0 for none
+	MinCandidates               int                  `json:"minCandidates"`               // Standing candidates needed for a valid result, 0 for none
+	NominationDeadline          time.Time            `json:"nominationDeadline"`          // Nominations close at this time; zero when they stay open until the election starts
+	HideLiveResults             bool                 `json:"hideLiveResults"`             // Tallies and selections are withheld until the election ends
+	Phase                       int                  `json:"phase"`                       // Polling phase currently open, 0 when none is
+	ConstituencyPhases          map[string]int       `json:"constituencyPhases"`          // Polling phase of each constituency; empty when polling is not phased
 }
 
 // Candidate represents a candidate in an election
 type Candidate struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Party       string `json:"party"`
-	Constituency string `json:"constituency"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Party         string `json:"party"`
+	Constituency  string `json:"constituency"`
+	ManifestoHash string `json:"manifestoHash,omitempty"` // Hash of the candidate's published platform document
+	ManifestoURL  string `json:"manifestoUrl,omitempty"`
+	RunningMateID string `json:"runningMateId,omitempty"` // Candidate paired with this one on a ticket
 }
 
 // Voter represents a registered voter
 type Voter struct {
-	ID           string `json:"id"` // Aadhar or Voter ID
-	Name         string `json:"name"`
-	Constituency string `json:"constituency"`
-	HasVoted     bool   `json:"hasVoted"`
+	ID            string `json:"id"` // Aadhar or Voter ID
+	Name          string `json:"name"`
+	Constituency  string `json:"constituency"`
+	HasVoted      bool   `json:"hasVoted"`                // Voted in any election; eligibility checks the election's own vote record
+	HasCredential bool   `json:"hasCredential,omitempty"` // Voting requires the credential stored under CREDENTIAL_, see CastVoteWithCredential
+	Redacted      bool   `json:"redacted,omitempty"`      // Personal data removed by RedactVoter
 }
 
 // Vote represents a cast vote
@@ -46,19 +175,44 @@ type Vote struct {
 	VoterID     string    `json:"voterId"`
 	CandidateID string    `json:"candidateId"`
 	Timestamp   time.Time `json:"timestamp"`
+	Late        bool      `json:"late"`              // Arrived after EndTime, within the grace period
+	TxID        string    `json:"txId"`              // Transaction that recorded the vote
+	ProxyID     string    `json:"proxyId,omitempty"` // Voter who cast the vote on the voter's behalf
 }
 
 // ElectionResult represents the result of an election
 type ElectionResult struct {
-	ElectionID  string         `json:"electionId"`
-	TotalVotes  int            `json:"totalVotes"`
-	CandidateResults []CandidateResult `json:"candidateResults"`
+	ElectionID        string            `json:"electionId"`
+	TotalVotes        int               `json:"totalVotes"`
+	CandidateResults  []CandidateResult `json:"candidateResults"`
+	NOTAVotes         int               `json:"notaVotes"`
+	BlankVotes        int               `json:"blankVotes"` // Counted in TotalVotes but not in percentages
+	WriteInVotes      int               `json:"writeInVotes"`
+	SpoiledVotes      int               `json:"spoiledVotes"`      // Counted in TotalVotes but not in percentages
+	AbstainVotes      int               `json:"abstainVotes"`      // Deliberate abstentions, counted in TotalVotes but not in percentages
+	WastedVotes       int               `json:"wastedVotes"`       // Votes for candidates who later withdrew
+	ExcludedLateVotes int               `json:"excludedLateVotes"` // Late votes left out because the election excludes them
+	ValidVotes        int               `json:"validVotes"`        // Candidate, write-in and NOTA votes
+	InvalidVotes      int               `json:"invalidVotes"`      // Blank and spoiled ballots; ValidVotes + InvalidVotes + AbstainVotes = TotalVotes
+	ValidVoteRatio    float64           `json:"validVoteRatio"`    // ValidVotes / TotalVotes, 0 when no votes were cast
+	ResultHash        string            `json:"resultHash"`        // SHA-256 over the canonical tally
 }
 
 // CandidateResult represents the result for a candidate
 type CandidateResult struct {
-	CandidateID string `json:"candidateId"`
-	VoteCount   int    `json:"voteCount"`
+	CandidateID        string  `json:"candidateId"`
+	Serial             int     `json:"serial"`
+	VoteCount          int     `json:"voteCount"`
+	Rank               int     `json:"rank"`                         // Competition rank by vote count, ties share a rank
+	Percentage         float64 `json:"percentage"`                   // Share of all ballots other than spoiled ones and abstentions
+	TiedAtSeatBoundary bool    `json:"tiedAtSeatBoundary,omitempty"` // Set by GetMultiWinnerResults
+	Withdrawn          bool    `json:"withdrawn"`
+	Name               string  `json:"name,omitempty"`              // Set by GetElectionResultsNamed
+	Party              string  `json:"party,omitempty"`             // Set by GetElectionResultsNamed
+	RunningMateID      string  `json:"runningMateId,omitempty"`     // Set by GetElectionResultsNamed
+	NominationFeePaid  bool    `json:"nominationFeePaid,omitempty"` // Set by GetElectionResults
+	FeeAmount          int     `json:"feeAmount,omitempty"`         // Set by GetElectionResults
+	FeeRefunded        bool    `json:"feeRefunded,omitempty"`       // Set by GetElectionResults: fee paid and deposit not forfeited
 }
 
 // InitLedger adds a base set of assets to the ledger
@@ -68,6 +222,28 @@ func (s *VotingContract) InitLedger(ctx contractapi.TransactionContextInterface)
 
 // CreateElection creates a new election
 func (s *VotingContract) CreateElection(ctx contractapi.TransactionContextInterface, id string, name string, description string, startTimeStr string, endTimeStr string, candidatesJSON string) error {
+	return s.createElection(ctx, id, name, description, startTimeStr, endTimeStr, candidatesJSON, false)
+}
+
+// CreateElectionSharedCandidates creates a new election, bypassing the
+// exclusive candidacy check when it is enabled. Admin only
+func (s *VotingContract) CreateElectionSharedCandidates(ctx contractapi.TransactionContextInterface, id string, name string, description string, startTimeStr string, endTimeStr string, candidatesJSON string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.createElection(ctx, id, name, description, startTimeStr, endTimeStr, candidatesJSON, true)
+}
+
+// createElection creates a new election, enforcing exclusive candidacy when
+// configured unless allowSharedCandidates is set
+func (s *VotingContract) createElection(ctx contractapi.TransactionContextInterface, id string, name string, description string, startTimeStr string, endTimeStr string, candidatesJSON string, allowSharedCandidates bool) error {
+	err := validateElectionID(id)
+	if err != nil {
+		return err
+	}
+
 	exists, err := s.ElectionExists(ctx, id)
 	if err != nil {
 		return err
@@ -91,23 +267,78 @@ func (s *VotingContract) CreateElection(ctx contractapi.TransactionContextInterf
 	if err != nil {
 		return fmt.Errorf("invalid candidates JSON: %v", err)
 	}
+	// Stray whitespace would otherwise break membership checks in CastVote
+	for i := range candidates {
+		candidates[i] = strings.TrimSpace(candidates[i])
+		if candidates[i] == "" {
+			return fmt.Errorf("candidate IDs must not be empty")
+		}
+	}
+
+	config, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if config.ExclusiveCandidacy && !allowSharedCandidates {
+		err = s.checkExclusiveCandidacy(ctx, candidates)
+		if err != nil {
+			return err
+		}
+	}
+	if config.MaxCandidatesPerConstituency > 0 {
+		err = s.checkCandidatesPerConstituency(ctx, candidates, config.MaxCandidatesPerConstituency)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Serials follow the creation order of the candidate list
+	serials := make(map[string]int)
+	for i, candidateID := range candidates {
+		serials[candidateID] = i + 1
+	}
 
 	election := Election{
-		ID:          id,
-		Name:        name,
-		Description: description,
-		StartTime:   startTime,
-		EndTime:     endTime,
-		Status:      "created",
-		Candidates:  candidates,
+		ID:               id,
+		Name:             name,
+		Description:      description,
+		StartTime:        startTime,
+		EndTime:          endTime,
+		Status:           "created",
+		Candidates:       candidates,
+		CandidateSerials: serials,
+	}
+
+	return s.putElection(ctx, &election)
+}
+
+// putElection stores an election, refusing to modify one whose results have
+// been certified
+func (s *VotingContract) putElection(ctx contractapi.TransactionContextInterface, election *Election) error {
+	err := checkNotCertified(election)
+	if err != nil {
+		return err
 	}
 
+	return s.storeElection(ctx, election)
+}
+
+// storeElection writes an election, bumping its version so that concurrent
+// editors can detect conflicting writes and stamping the modification time
+func (s *VotingContract) storeElection(ctx contractapi.TransactionContextInterface, election *Election) error {
+	modifiedAt, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	election.Version++
+	election.LastModified = modifiedAt
+
 	electionJSON, err := json.Marshal(election)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, electionJSON)
+	return ctx.GetStub().PutState(election.ID, electionJSON)
 }
 
 // ElectionExists returns true when election with given ID exists in world state
@@ -139,47 +370,318 @@ func (s *VotingContract) GetElection(ctx contractapi.TransactionContextInterface
 	return &election, nil
 }
 
-// UpdateElectionStatus updates the status of an election
+// UpdateElectionStatus updates the status of an election. With the
+// StrictElectionEnd setting, an election cannot be ended before its EndTime
 func (s *VotingContract) UpdateElectionStatus(ctx contractapi.TransactionContextInterface, id string, status string) error {
+	config, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.setElectionStatus(ctx, id, status, config.StrictElectionEnd)
+}
+
+// EndElection ends an election, refusing to do so before its EndTime unless
+// force is set. Forcing an early end is admin only
+func (s *VotingContract) EndElection(ctx contractapi.TransactionContextInterface, id string, force bool) error {
+	if force {
+		err := requireAdmin(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.setElectionStatus(ctx, id, "ended", !force)
+}
+
+// setElectionStatus updates the status of an election. When strict is set,
+// ending an election before its EndTime is refused
+func (s *VotingContract) setElectionStatus(ctx contractapi.TransactionContextInterface, id string, status string, strict bool) error {
 	election, err := s.GetElection(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if status != "created" && status != "active" && status != "ended" {
-		return fmt.Errorf("invalid status: %s. Status must be 'created', 'active', or 'ended'", status)
+	status, err = normalizeElectionStatus(status)
+	if err != nil {
+		return err
+	}
+
+	if strict && status == "ended" && election.Status != "ended" {
+		now, err := getTxTime(ctx)
+		if err != nil {
+			return err
+		}
+		if now.Before(election.EndTime) {
+			return fmt.Errorf("election %s cannot be ended before its end time %s without force", id, election.EndTime.Format(time.RFC3339))
+		}
 	}
 
 	election.Status = status
 
-	electionJSON, err := json.Marshal(election)
+	return s.putElection(ctx, election)
+}
+
+// checkElectionVersion returns an error unless the election is at the expected version
+func checkElectionVersion(election *Election, expectedVersion int) error {
+	if election.Version != expectedVersion {
+		return fmt.Errorf("election %s was modified concurrently: expected version %d but found %d", election.ID, expectedVersion, election.Version)
+	}
+	return nil
+}
+
+// UpdateElectionStatusWithVersion updates the status of an election only if
+// it is still at the expected version
+func (s *VotingContract) UpdateElectionStatusWithVersion(ctx contractapi.TransactionContextInterface, id string, status string, expectedVersion int) error {
+	election, err := s.GetElection(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = checkElectionVersion(election, expectedVersion)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, electionJSON)
+	return s.UpdateElectionStatus(ctx, id, status)
+}
+
+// UpdateElection updates the descriptive fields and voting window of an
+// election that has not started yet, provided it is still at the expected version
+func (s *VotingContract) UpdateElection(ctx contractapi.TransactionContextInterface, id string, name string, description string, startTimeStr string, endTimeStr string, expectedVersion int) error {
+	election, err := s.GetElection(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = checkElectionVersion(election, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if election.Status != "created" {
+		return fmt.Errorf("election %s can only be updated while in 'created' status", id)
+	}
+
+	startTime, err := time.Parse(time.RFC3339, startTimeStr)
+	if err != nil {
+		return fmt.Errorf("invalid start time format: %v", err)
+	}
+
+	endTime, err := time.Parse(time.RFC3339, endTimeStr)
+	if err != nil {
+		return fmt.Errorf("invalid end time format: %v", err)
+	}
+
+	election.Name = name
+	election.Description = description
+	election.StartTime = startTime
+	election.EndTime = endTime
+
+	return s.putElection(ctx, election)
+}
+
+// DeleteElection removes an election that has not started yet together with
+// the records kept for it, such as its frozen roll, seal and nominations, so
+// that a new election reusing the ID starts afresh. Vote records for it are
+// never expected at that point, so any found are reported as corruption
+// instead of being left orphaned. Admin only
+func (s *VotingContract) DeleteElection(ctx contractapi.TransactionContextInterface, id string) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	election, err := s.GetElection(ctx, id)
+	if err != nil {
+		return err
+	}
+	if election.Status != "created" {
+		return fmt.Errorf("election %s can only be deleted while in 'created' status", id)
+	}
+
+	votes, err := s.getElectionVotes(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(votes) > 0 {
+		return fmt.Errorf("election %s has %d vote records despite never having started; refusing to delete possibly corrupted data", id, len(votes))
+	}
+
+	err = s.deleteElectionRecords(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(id)
+}
+
+// electionRecordPrefixes lists the prefixes of records stored once per
+// election under PREFIX_<electionID>
+var electionRecordPrefixes = []string{"ROLL_", "SEAL_", "VOTECOUNT_", "RESULT_", "TIEBREAK_"}
+
+// electionRecordSetPrefixes lists the prefixes of records stored per election
+// under PREFIX_<electionID>_<suffix>
+var electionRecordSetPrefixes = []string{"NOMINATION_", "FEE_", "CHECKIN_", "SNAPSHOT_", "THRESHOLD_", "ATTEMPT_"}
+
+// deleteElectionRecords removes the records kept for an election other than
+// the election itself and its votes, including the idempotency records that
+// created it
+func (s *VotingContract) deleteElectionRecords(ctx contractapi.TransactionContextInterface, electionID string) error {
+	var keys []string
+	for _, prefix := range electionRecordPrefixes {
+		keys = append(keys, prefix+electionID)
+	}
+	for _, prefix := range append(electionRecordSetPrefixes, "IDEMPOTENCY_") {
+		if prefix != "IDEMPOTENCY_" {
+			prefix += electionID + "_"
+		}
+		matched, err := electionRecordKeys(ctx, prefix, electionID)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, matched...)
+	}
+
+	for _, key := range keys {
+		err := ctx.GetStub().DelState(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// electionRecordKeys returns the keys starting with prefix whose records
+// belong to the given election
+func electionRecordKeys(ctx contractapi.TransactionContextInterface, prefix string, electionID string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(prefix, prefixRangeEnd(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	keys := []string{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		// Election IDs may share a prefix, e.g. "E1" and "E1_B"
+		var record struct {
+			ElectionID string `json:"electionId"`
+		}
+		err = json.Unmarshal(queryResponse.Value, &record)
+		if err != nil || record.ElectionID != electionID {
+			continue
+		}
+		keys = append(keys, queryResponse.Key)
+	}
+
+	return keys, nil
+}
+
+// effectiveStatus returns the status an election should have at the given
+// time according to its voting window, which stays open for late votes
+// during any grace period. Ended elections stay ended
+func effectiveStatus(election *Election, now time.Time) string {
+	if election.Status == "ended" {
+		return "ended"
+	}
+	if now.Before(election.StartTime) {
+		return "created"
+	}
+	if now.After(votingDeadline(election)) {
+		return "ended"
+	}
+	return "active"
+}
+
+// GetEffectiveStatus returns the status an election should have at the
+// transaction timestamp, without persisting any change
+func (s *VotingContract) GetEffectiveStatus(ctx contractapi.TransactionContextInterface, electionID string) (string, error) {
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return "", err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return effectiveStatus(election, now), nil
+}
+
+// EndReport lists the elections closed by EndExpiredElections
+type EndReport struct {
+	Ended []string `json:"ended"`
+}
+
+// EndExpiredElections ends every active election whose voting window,
+// including any grace period, has passed at the transaction timestamp. Admin only
+func (s *VotingContract) EndExpiredElections(ctx contractapi.TransactionContextInterface) (*EndReport, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	elections, err := s.GetAllElections(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := EndReport{
+		Ended: []string{},
+	}
+	for _, election := range elections {
+		if election.Status != "active" || !now.After(votingDeadline(election)) {
+			continue
+		}
+		election.Status = "ended"
+		err = s.putElection(ctx, election)
+		if err != nil {
+			return nil, err
+		}
+		report.Ended = append(report.Ended, election.ID)
+	}
+
+	return &report, nil
 }
 
-// GetAllElections returns all elections found in world state
-func (s *VotingContract) GetAllElections(ctx contractapi.TransactionContextInterface) ([]*Election, error) {
+// GetAllElections returns all elections found in world state. Test elections
+// are only included when includeTest is set
+func (s *VotingContract) GetAllElections(ctx contractapi.TransactionContextInterface, includeTest bool) ([]*Election, error) {
 	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
 	if err != nil {
 		return nil, err
 	}
 	defer resultsIterator.Close()
 
-	var elections []*Election
+	elections := []*Election{}
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
 		if err != nil {
 			return nil, err
 		}
+		if !isElectionKey(queryResponse.Key) {
+			continue
+		}
 
 		var election Election
 		err = json.Unmarshal(queryResponse.Value, &election)
 		if err != nil {
 			continue // Skip non-election assets
 		}
+		if election.TestMode && !includeTest {
+			continue
+		}
 		elections = append(elections, &election)
 	}
 
@@ -198,19 +700,22 @@ func (s *VotingContract) RegisterCandidate(ctx contractapi.TransactionContextInt
 		return fmt.Errorf("the candidate %s already exists", id)
 	}
 
-	candidate := Candidate{
-		ID:          id,
-		Name:        name,
-		Party:       party,
+	return s.putCandidate(ctx, &Candidate{
+		ID:           id,
+		Name:         name,
+		Party:        party,
 		Constituency: constituency,
-	}
+	})
+}
 
-	candidateJSON, err = json.Marshal(candidate)
+// putCandidate writes a candidate record to the world state
+func (s *VotingContract) putCandidate(ctx contractapi.TransactionContextInterface, candidate *Candidate) error {
+	candidateJSON, err := json.Marshal(candidate)
 	if err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(candidateKey, candidateJSON)
+	return ctx.GetStub().PutState("CANDIDATE_"+candidate.ID, candidateJSON)
 }
 
 // GetCandidate returns the candidate stored in the world state with given id
@@ -234,6 +739,32 @@ func (s *VotingContract) GetCandidate(ctx contractapi.TransactionContextInterfac
 	return &candidate, nil
 }
 
+// GetAllCandidates returns all registered candidates found in world state
+func (s *VotingContract) GetAllCandidates(ctx contractapi.TransactionContextInterface) ([]*Candidate, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("CANDIDATE_", prefixRangeEnd("CANDIDATE_"))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	candidates := []*Candidate{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var candidate Candidate
+		err = json.Unmarshal(queryResponse.Value, &candidate)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &candidate)
+	}
+
+	return candidates, nil
+}
+
 // RegisterVoter registers a new voter
 func (s *VotingContract) RegisterVoter(ctx contractapi.TransactionContextInterface, id string, name string, constituency string) error {
 	voterKey := "VOTER_" + id
@@ -261,6 +792,25 @@ func (s *VotingContract) RegisterVoter(ctx contractapi.TransactionContextInterfa
 	return ctx.GetStub().PutState(voterKey, voterJSON)
 }
 
+// RegisterVoterStrict registers a new voter after checking the ID against
+// the configured voter ID pattern
+func (s *VotingContract) RegisterVoterStrict(ctx contractapi.TransactionContextInterface, id string, name string, constituency string) error {
+	config, err := getConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	pattern, err := regexp.Compile(config.VoterIDPattern)
+	if err != nil {
+		return fmt.Errorf("invalid voter ID pattern: %v", err)
+	}
+	if !pattern.MatchString(id) {
+		return fmt.Errorf("malformed voter ID %s: must match %s", id, config.VoterIDPattern)
+	}
+
+	return s.RegisterVoter(ctx, id, name, constituency)
+}
+
 // GetVoter returns the voter stored in the world state with given id
 func (s *VotingContract) GetVoter(ctx contractapi.TransactionContextInterface, id string) (*Voter, error) {
 	voterKey := "VOTER_" + id
@@ -282,50 +832,86 @@ func (s *VotingContract) GetVoter(ctx contractapi.TransactionContextInterface, i
 	return &voter, nil
 }
 
-// CastVote casts a vote for a candidate in an election
-func (s *VotingContract) CastVote(ctx contractapi.TransactionContextInterface, electionID string, voterID string, candidateID string) error {
-	// Check if election exists and is active
-	election, err := s.GetElection(ctx, electionID)
+// getAllVoters returns all registered voters found in world state
+func (s *VotingContract) getAllVoters(ctx contractapi.TransactionContextInterface) ([]*Voter, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("VOTER_", prefixRangeEnd("VOTER_"))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if election.Status != "active" {
-		return fmt.Errorf("election is not active")
+	defer resultsIterator.Close()
+
+	voters := []*Voter{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var voter Voter
+		err = json.Unmarshal(queryResponse.Value, &voter)
+		if err != nil {
+			return nil, err
+		}
+		voters = append(voters, &voter)
 	}
 
-	// Check if current time is within election period
-	currentTime := time.Now()
-	if currentTime.Before(election.StartTime) || currentTime.After(election.EndTime) {
-		return fmt.Errorf("election is not currently open for voting")
+	return voters, nil
+}
+
+// CastVote casts a vote for a candidate in an election. A refused vote is
+// reported in the outcome, not as an error. Voters registered with a
+// credential hash must vote through CastVoteWithCredential
+func (s *VotingContract) CastVote(ctx contractapi.TransactionContextInterface, electionID string, voterID string, candidateID string) (*VoteOutcome, error) {
+	_, err := s.castVote(ctx, electionID, voterID, candidateID, "", "")
+	return voteOutcome(err)
+}
+
+// castVote records a vote after checking the voter's eligibility, credential
+// and selection, and returns it. Once the voter is known to exist, every
+// attempt is recorded for the cooldown and a refusal is returned as a
+// VoteRefusedError. proxyID names the voter casting it on their behalf, if any
+func (s *VotingContract) castVote(ctx contractapi.TransactionContextInterface, electionID string, voterID string, candidateID string, credential string, proxyID string) (*Vote, error) {
+	// Check if election exists
+	election, err := s.GetElection(ctx, electionID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if voter exists
-	voter, err := s.GetVoter(ctx, voterID)
+	currentTime, err := getTxTime(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Check if voter has already voted
-	if voter.HasVoted {
-		return fmt.Errorf("voter has already cast a vote")
+	// Check the election, the voting window and the voter
+	report, voter, err := s.evaluateEligibility(ctx, election, voterID, currentTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.VoterExists {
+		return nil, errors.New(report.Reasons[0])
 	}
 
-	// Check if candidate exists and is part of the election
-	_, err = s.GetCandidate(ctx, candidateID)
+	// Every attempt restarts the cooldown, including refused ones
+	err = s.recordVoteAttempt(ctx, electionID, voterID, currentTime)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if !report.Eligible {
+		return nil, &VoteRefusedError{Reason: report.Reasons[0]}
 	}
 
-	// Check if candidate is in the election
-	candidateFound := false
-	for _, cID := range election.Candidates {
-		if cID == candidateID {
-			candidateFound = true
-			break
-		}
+	// Check the credential of voters registered with one
+	err = checkVoterCredential(ctx, voter, credential)
+	if err != nil {
+		return nil, &VoteRefusedError{Reason: err.Error()}
 	}
-	if !candidateFound {
-		return fmt.Errorf("candidate is not part of this election")
+
+	// Check the selection against the ballot
+	candidateID = strings.TrimSpace(candidateID)
+	err = s.checkBallotSelection(ctx, election, candidateID)
+	if err != nil {
+		return nil, &VoteRefusedError{Reason: err.Error()}
 	}
 
 	// Create vote
@@ -335,89 +921,75 @@ func (s *VotingContract) CastVote(ctx contractapi.TransactionContextInterface, e
 		VoterID:     voterID,
 		CandidateID: candidateID,
 		Timestamp:   currentTime,
+		Late:        currentTime.After(election.EndTime),
+		TxID:        ctx.GetStub().GetTxID(),
+		ProxyID:     proxyID,
 	}
 
 	voteJSON, err := json.Marshal(vote)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update voter's status
 	voter.HasVoted = true
 	voterJSON, err := json.Marshal(voter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Store vote and update voter status
 	err = ctx.GetStub().PutState(voteKey, voteJSON)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = s.countVote(ctx, election)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.GetStub().PutState("VOTER_"+voterID, voterJSON)
+	if err != nil {
+		return nil, err
 	}
 
-	return ctx.GetStub().PutState("VOTER_"+voterID, voterJSON)
+	return &vote, nil
 }
 
-// GetElectionResults gets the results of an election
-func (s *VotingContract) GetElectionResults(ctx contractapi.TransactionContextInterface, electionID string) (*ElectionResult, error) {
+// GetElectionResults gets the results of an election. Results of test
+// elections are only returned when includeTest is set
+func (s *VotingContract) GetElectionResults(ctx contractapi.TransactionContextInterface, electionID string, includeTest bool) (*ElectionResult, error) {
 	// Check if election exists
 	election, err := s.GetElection(ctx, electionID)
 	if err != nil {
 		return nil, err
 	}
+	if election.TestMode && !includeTest {
+		return nil, fmt.Errorf("election %s is a test election", electionID)
+	}
 
 	// Check if election has ended
 	if election.Status != "ended" {
-		return nil, fmt.Errorf("election has not ended yet")
-	}
-
-	// Initialize result
-	result := ElectionResult{
-		ElectionID:       electionID,
-		TotalVotes:       0,
-		CandidateResults: []CandidateResult{},
-	}
-
-	// Initialize vote counts for each candidate
-	candidateVotes := make(map[string]int)
-	for _, candidateID := range election.Candidates {
-		candidateVotes[candidateID] = 0
+		return nil, &ElectionNotEndedError{ElectionID: electionID, Status: election.Status}
 	}
 
-	// Query all votes for this election
-	voteIterator, err := ctx.GetStub().GetStateByPartialCompositeKey("VOTE_"+electionID, []string{})
+	err = checkResultsReleasable(ctx, election)
 	if err != nil {
 		return nil, err
 	}
-	defer voteIterator.Close()
-
-	// Count votes
-	for voteIterator.HasNext() {
-		queryResponse, err := voteIterator.Next()
-		if err != nil {
-			return nil, err
-		}
 
-		var vote Vote
-		err = json.Unmarshal(queryResponse.Value, &vote)
-		if err != nil {
-			continue
-		}
-
-		candidateVotes[vote.CandidateID]++
-		result.TotalVotes++
+	result, err := s.tallyElection(ctx, election)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create candidate results
-	for candidateID, voteCount := range candidateVotes {
-		candidateResult := CandidateResult{
-			CandidateID: candidateID,
-			VoteCount:   voteCount,
-		}
-		result.CandidateResults = append(result.CandidateResults, candidateResult)
+	err = s.reportNominationFees(ctx, election, result)
+	if err != nil {
+		return nil, err
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 func main() {
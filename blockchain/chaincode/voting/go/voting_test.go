@@ -0,0 +1,687 @@
+package main
+
+import (
+	"container/list"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// testNow is the transaction time tests start at
+var testNow = time.Date(2030, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+// mockIdentity is a client identity with a fixed ID, organisation and attributes
+type mockIdentity struct {
+	id         string
+	mspID      string
+	attributes map[string]string
+}
+
+func (m *mockIdentity) GetID() (string, error) {
+	return m.id, nil
+}
+
+func (m *mockIdentity) GetMSPID() (string, error) {
+	return m.mspID, nil
+}
+
+func (m *mockIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := m.attributes[attrName]
+	return value, found, nil
+}
+
+func (m *mockIdentity) AssertAttributeValue(attrName string, attrValue string) error {
+	value, found := m.attributes[attrName]
+	if !found || value != attrValue {
+		return fmt.Errorf("attribute %s does not have value %s", attrName, attrValue)
+	}
+	return nil
+}
+
+func (m *mockIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, nil
+}
+
+// testEnv wires the contract to a mock stub and client identity
+type testEnv struct {
+	t        *testing.T
+	contract *VotingContract
+	ctx      *contractapi.TransactionContext
+	stub     *shimtest.MockStub
+	txCount  int
+	now      time.Time
+}
+
+// newTestEnv returns an environment acting as an admin at testNow
+func newTestEnv(t *testing.T) *testEnv {
+	stub := shimtest.NewMockStub("voting", nil)
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+
+	env := &testEnv{
+		t:        t,
+		contract: &VotingContract{},
+		ctx:      ctx,
+		stub:     stub,
+	}
+	env.asAdmin()
+	env.newTx()
+	env.setTime(testNow)
+	return env
+}
+
+// newTx starts a new mock transaction with its own ID, keeping the current time
+func (e *testEnv) newTx() {
+	e.txCount++
+	e.stub.MockTransactionStart(fmt.Sprintf("tx%d", e.txCount))
+	if !e.now.IsZero() {
+		e.setTime(e.now)
+	}
+}
+
+// setTime sets the transaction timestamp
+func (e *testEnv) setTime(now time.Time) {
+	e.now = now
+	e.stub.TxTimestamp.Seconds = now.Unix()
+	e.stub.TxTimestamp.Nanos = int32(now.Nanosecond())
+}
+
+// advance moves the transaction timestamp forward
+func (e *testEnv) advance(d time.Duration) {
+	e.setTime(e.now.Add(d))
+}
+
+// asIdentity makes later calls come from the given identity
+func (e *testEnv) asIdentity(id string, mspID string, attributes map[string]string) {
+	e.ctx.SetClientIdentity(&mockIdentity{id: id, mspID: mspID, attributes: attributes})
+}
+
+// asAdmin makes later calls come from an admin organisation
+func (e *testEnv) asAdmin() {
+	e.asIdentity("admin", "ElectionCommissionMSP", nil)
+}
+
+// asUser makes later calls come from an ordinary client
+func (e *testEnv) asUser() {
+	e.asIdentity("user", "Org1MSP", nil)
+}
+
+// asCandidate makes later calls come from a client acting for the candidate
+func (e *testEnv) asCandidate(candidateID string) {
+	e.asIdentity("candidate-"+candidateID, "Org1MSP", map[string]string{candidateIDAttribute: candidateID})
+}
+
+// must fails the test on an unexpected error
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// mustFail fails the test unless err is set and mentions want
+func mustFail(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected an error containing %q, got none", want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected an error containing %q, got %q", want, err.Error())
+	}
+}
+
+// registerCandidates registers candidates in a constituency
+func (e *testEnv) registerCandidates(constituency string, ids ...string) {
+	e.t.Helper()
+	for _, id := range ids {
+		must(e.t, e.contract.RegisterCandidate(e.ctx, id, "Name "+id, "Party "+id, constituency))
+	}
+}
+
+// registerVoters registers voters in a constituency
+func (e *testEnv) registerVoters(constituency string, ids ...string) {
+	e.t.Helper()
+	for _, id := range ids {
+		must(e.t, e.contract.RegisterVoter(e.ctx, id, "Voter "+id, constituency))
+	}
+}
+
+// createElection creates an election open from an hour before to an hour after the current time
+func (e *testEnv) createElection(id string, candidates ...string) {
+	e.t.Helper()
+	must(e.t, e.contract.CreateElection(e.ctx, id, "Election "+id, "", e.now.Add(-time.Hour).Format(time.RFC3339), e.now.Add(time.Hour).Format(time.RFC3339), candidatesJSON(candidates)))
+}
+
+// configure applies election options given as JSON
+func (e *testEnv) configure(id string, optionsJSON string) {
+	e.t.Helper()
+	must(e.t, e.contract.ConfigureElection(e.ctx, id, optionsJSON))
+}
+
+// setStatus changes the status of an election
+func (e *testEnv) setStatus(id string, status string) {
+	e.t.Helper()
+	must(e.t, e.contract.UpdateElectionStatus(e.ctx, id, status))
+}
+
+// setConfig updates the contract configuration
+func (e *testEnv) setConfig(configJSON string) {
+	e.t.Helper()
+	must(e.t, e.contract.SetConfig(e.ctx, configJSON))
+}
+
+// submit runs a call in its own transaction and, like a Fabric peer, discards
+// every write of a call that returns an error
+func (e *testEnv) submit(call func() error) error {
+	e.newTx()
+	state := make(map[string][]byte, len(e.stub.State))
+	for key, value := range e.stub.State {
+		state[key] = value
+	}
+	keys := list.New()
+	keys.PushBackList(e.stub.Keys)
+
+	err := call()
+	if err != nil {
+		e.stub.State = state
+		e.stub.Keys = keys
+	}
+	return err
+}
+
+// submitVote submits a vote call and returns the reason of a refused vote as
+// an error, after its transaction has committed
+func (e *testEnv) submitVote(call func() (*VoteOutcome, error)) error {
+	var outcome *VoteOutcome
+	err := e.submit(func() error {
+		var err error
+		outcome, err = call()
+		return err
+	})
+	if err == nil && !outcome.Accepted {
+		return errors.New(outcome.Reason)
+	}
+	return err
+}
+
+// vote casts a vote in its own transaction
+func (e *testEnv) vote(electionID string, voterID string, candidateID string) error {
+	return e.submitVote(func() (*VoteOutcome, error) {
+		return e.contract.CastVote(e.ctx, electionID, voterID, candidateID)
+	})
+}
+
+// mustVote casts a vote that must be accepted
+func (e *testEnv) mustVote(electionID string, voterID string, candidateID string) {
+	e.t.Helper()
+	must(e.t, e.vote(electionID, voterID, candidateID))
+}
+
+// election returns the stored election
+func (e *testEnv) election(id string) *Election {
+	e.t.Helper()
+	election, err := e.contract.GetElection(e.ctx, id)
+	must(e.t, err)
+	return election
+}
+
+// candidatesJSON encodes candidate IDs as a JSON array
+func candidatesJSON(ids []string) string {
+	quoted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		quoted = append(quoted, fmt.Sprintf("%q", id))
+	}
+	return "[" + strings.Join(quoted, ",") + "]"
+}
+
+// setupElection registers candidates C1 and C2 in constituency North with
+// voters V1 to V4, and an active election E1 listing both candidates
+func setupElection(t *testing.T) *testEnv {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1", "C2")
+	env.registerVoters("North", "V1", "V2", "V3", "V4")
+	env.createElection("E1", "C1", "C2")
+	env.setStatus("E1", "active")
+	return env
+}
+
+// endElection ends an election
+func (e *testEnv) endElection(id string) {
+	e.t.Helper()
+	e.setStatus(id, "ended")
+}
+
+// results returns the results of an ended election
+func (e *testEnv) results(id string) *ElectionResult {
+	e.t.Helper()
+	result, err := e.contract.GetElectionResults(e.ctx, id, true)
+	must(e.t, err)
+	return result
+}
+
+// candidateVotes returns the votes of each candidate in a result
+func candidateVotes(result *ElectionResult) map[string]int {
+	votes := make(map[string]int)
+	for _, candidateResult := range result.CandidateResults {
+		votes[candidateResult.CandidateID] = candidateResult.VoteCount
+	}
+	return votes
+}
+
+func TestTestElectionsHiddenByDefault(t *testing.T) {
+	env := newTestEnv(t)
+	env.registerCandidates("North", "C1")
+	env.registerVoters("North", "V1")
+	env.createElection("REAL", "C1")
+	env.createElection("TEST", "C1")
+	env.configure("TEST", `{"testMode":true}`)
+	env.setStatus("TEST", "active")
+	env.mustVote("TEST", "V1", "C1")
+	env.endElection("TEST")
+
+	tests := []struct {
+		name        string
+		includeTest bool
+		want        []string
+	}{
+		{"default", false, []string{"REAL"}},
+		{"including test elections", true, []string{"REAL", "TEST"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elections, err := env.contract.GetAllElections(env.ctx, tt.includeTest)
+			must(t, err)
+			var ids []string
+			for _, election := range elections {
+				ids = append(ids, election.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("elections = %v, want %v", ids, tt.want)
+			}
+
+			result, err := env.contract.GetElectionResults(env.ctx, "TEST", tt.includeTest)
+			if !tt.includeTest {
+				mustFail(t, err, "is a test election")
+				return
+			}
+			must(t, err)
+			if result.TotalVotes != 1 {
+				t.Errorf("test election has %d votes, want 1", result.TotalVotes)
+			}
+		})
+	}
+}
+
+func TestCreateElectionValidatesID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"empty", "", "must not be empty"},
+		{"blank", "   ", "must not be empty"},
+		{"voter prefix", "VOTER_1", "reserved prefix VOTER_"},
+		{"candidate prefix", "CANDIDATE_C1", "reserved prefix CANDIDATE_"},
+		{"vote prefix", "VOTE_E1_V1", "reserved prefix VOTE_"},
+		{"config prefix", "CONFIG_CONTRACT", "reserved prefix CONFIG_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerVoters("North", "1")
+			err := env.contract.CreateElection(env.ctx, tt.id, "Election", "", testNow.Format(time.RFC3339), testNow.Add(time.Hour).Format(time.RFC3339), "[]")
+			mustFail(t, err, tt.want)
+
+			voter, err := env.contract.GetVoter(env.ctx, "1")
+			must(t, err)
+			if voter.Name != "Voter 1" {
+				t.Errorf("voter record was overwritten: %+v", voter)
+			}
+		})
+	}
+
+	env := newTestEnv(t)
+	must(t, env.contract.CreateElection(env.ctx, "E_VOTER_1", "Election", "", testNow.Format(time.RFC3339), testNow.Add(time.Hour).Format(time.RFC3339), "[]"))
+}
+
+func TestGetEffectiveStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		offset time.Duration // Transaction time relative to the election start
+		want   string
+	}{
+		{"before the start", "created", -time.Minute, "created"},
+		{"created but within the window", "created", 30 * time.Minute, "active"},
+		{"active within the window", "active", time.Hour, "active"},
+		{"active after the end", "active", 3 * time.Hour, "ended"},
+		{"ended early", "ended", time.Minute, "ended"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.createElection("E1")
+			if tt.status != "created" {
+				env.setStatus("E1", tt.status)
+			}
+			version := env.election("E1").Version
+
+			env.setTime(env.election("E1").StartTime.Add(tt.offset))
+			status, err := env.contract.GetEffectiveStatus(env.ctx, "E1")
+			must(t, err)
+			if status != tt.want {
+				t.Errorf("effective status = %s, want %s", status, tt.want)
+			}
+			if election := env.election("E1"); election.Status != tt.status || election.Version != version {
+				t.Errorf("stored election changed to %s at version %d", election.Status, election.Version)
+			}
+		})
+	}
+}
+
+func TestElectionVersion(t *testing.T) {
+	env := newTestEnv(t)
+	env.createElection("E1")
+	version := env.election("E1").Version
+	if version != 1 {
+		t.Fatalf("new election is at version %d, want 1", version)
+	}
+
+	start := testNow.Add(time.Hour).Format(time.RFC3339)
+	end := testNow.Add(2 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		update  func(expectedVersion int) error
+		version int // Relative to the current version
+		want    string
+	}{
+		{"update at the current version", func(v int) error {
+			return env.contract.UpdateElection(env.ctx, "E1", "Renamed", "", start, end, v)
+		}, 0, ""},
+		{"update at a stale version", func(v int) error {
+			return env.contract.UpdateElection(env.ctx, "E1", "Stale", "", start, end, v)
+		}, -1, "modified concurrently"},
+		{"status change at a stale version", func(v int) error {
+			return env.contract.UpdateElectionStatusWithVersion(env.ctx, "E1", "active", v)
+		}, -1, "modified concurrently"},
+		{"status change at the current version", func(v int) error {
+			return env.contract.UpdateElectionStatusWithVersion(env.ctx, "E1", "active", v)
+		}, 0, ""},
+		{"update once started", func(v int) error {
+			return env.contract.UpdateElection(env.ctx, "E1", "Late", "", start, end, v)
+		}, 0, "only be updated while in 'created' status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := env.election("E1").Version
+			err := tt.update(before + tt.version)
+			after := env.election("E1").Version
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				if after != before {
+					t.Errorf("version moved from %d to %d despite the refusal", before, after)
+				}
+				return
+			}
+			must(t, err)
+			if after != before+1 {
+				t.Errorf("version = %d, want %d", after, before+1)
+			}
+		})
+	}
+
+	if name := env.election("E1").Name; name != "Renamed" {
+		t.Errorf("name = %s, want Renamed", name)
+	}
+}
+
+func TestEndExpiredElections(t *testing.T) {
+	env := newTestEnv(t)
+	create := func(id string, end time.Duration, status string) {
+		must(t, env.contract.CreateElection(env.ctx, id, "Election "+id, "", testNow.Add(-3*time.Hour).Format(time.RFC3339), testNow.Add(end).Format(time.RFC3339), "[]"))
+		if status != "created" {
+			env.setStatus(id, status)
+		}
+	}
+	create("EXPIRED", -time.Hour, "active")
+	create("OPEN", time.Hour, "active")
+	create("GRACE", -30*time.Second, "created")
+	env.configure("GRACE", `{"gracePeriodSeconds":60}`)
+	env.setStatus("GRACE", "active")
+	create("NOT_STARTED", -time.Hour, "created")
+	create("TEST", -time.Hour, "created")
+	env.configure("TEST", `{"testMode":true}`)
+	env.setStatus("TEST", "active")
+
+	env.asUser()
+	_, err := env.contract.EndExpiredElections(env.ctx)
+	mustFail(t, err, "access denied")
+	if status := env.election("EXPIRED").Status; status != "active" {
+		t.Fatalf("refused call changed EXPIRED to %s", status)
+	}
+
+	env.asAdmin()
+	report, err := env.contract.EndExpiredElections(env.ctx)
+	must(t, err)
+	if strings.Join(report.Ended, ",") != "EXPIRED,TEST" {
+		t.Errorf("ended = %v, want [EXPIRED TEST]", report.Ended)
+	}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"EXPIRED", "ended"},
+		{"OPEN", "active"},
+		{"GRACE", "active"},
+		{"NOT_STARTED", "created"},
+		{"TEST", "ended"},
+	}
+	for _, tt := range tests {
+		if status := env.election(tt.id).Status; status != tt.want {
+			t.Errorf("%s is %s, want %s", tt.id, status, tt.want)
+		}
+	}
+
+	// Once the grace period has passed the remaining election ends too
+	env.advance(time.Minute)
+	report, err = env.contract.EndExpiredElections(env.ctx)
+	must(t, err)
+	if strings.Join(report.Ended, ",") != "GRACE" {
+		t.Errorf("ended = %v, want [GRACE]", report.Ended)
+	}
+}
+
+func TestDeleteElection(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(env *testEnv)
+		want  string
+	}{
+		{"created election", func(env *testEnv) {}, ""},
+		{"active election", func(env *testEnv) { env.setStatus("E1", "active") }, "only be deleted while in 'created' status"},
+		{"vote records despite never starting", func(env *testEnv) {
+			voteJSON, err := json.Marshal(Vote{ElectionID: "E1", VoterID: "V1", CandidateID: "C1"})
+			must(env.t, err)
+			must(env.t, env.stub.PutState("VOTE_E1_V1", voteJSON))
+		}, "has 1 vote records despite never having started"},
+		{"caller is not an admin", func(env *testEnv) { env.asUser() }, "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1")
+			env.createElection("E1", "C1")
+			tt.setup(env)
+
+			err := env.contract.DeleteElection(env.ctx, "E1")
+			exists, existsErr := env.contract.ElectionExists(env.ctx, "E1")
+			must(t, existsErr)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				if !exists {
+					t.Errorf("election was deleted despite the refusal")
+				}
+				return
+			}
+			must(t, err)
+			if exists {
+				t.Fatalf("election still exists after deletion")
+			}
+			// The ID can be reused
+			env.createElection("E1")
+		})
+	}
+}
+
+func TestStrictElectionEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		after  time.Duration // Transaction time relative to the election end
+		end    func(env *testEnv) error
+		want   string
+	}{
+		{"lenient early end", false, -time.Minute, func(env *testEnv) error {
+			return env.contract.UpdateElectionStatus(env.ctx, "E1", "ended")
+		}, ""},
+		{"strict early end", true, -time.Minute, func(env *testEnv) error {
+			return env.contract.UpdateElectionStatus(env.ctx, "E1", "ended")
+		}, "cannot be ended before its end time"},
+		{"strict end after the window", true, time.Second, func(env *testEnv) error {
+			return env.contract.UpdateElectionStatus(env.ctx, "E1", "ended")
+		}, ""},
+		{"EndElection early without force", false, -time.Minute, func(env *testEnv) error {
+			return env.contract.EndElection(env.ctx, "E1", false)
+		}, "cannot be ended before its end time"},
+		{"EndElection forced early", true, -time.Minute, func(env *testEnv) error {
+			return env.contract.EndElection(env.ctx, "E1", true)
+		}, ""},
+		{"EndElection forced by a non-admin", false, -time.Minute, func(env *testEnv) error {
+			env.asUser()
+			return env.contract.EndElection(env.ctx, "E1", true)
+		}, "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := setupElection(t)
+			if tt.strict {
+				env.setConfig(`{"strictElectionEnd":true}`)
+			}
+			env.setTime(env.election("E1").EndTime.Add(tt.after))
+
+			err := tt.end(env)
+			status := env.election("E1").Status
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				if status != "active" {
+					t.Errorf("status = %s despite the refusal", status)
+				}
+				return
+			}
+			must(t, err)
+			if status != "ended" {
+				t.Errorf("status = %s, want ended", status)
+			}
+		})
+	}
+}
+
+func TestListQueriesReturnEmptyArrays(t *testing.T) {
+	tests := []struct {
+		name  string
+		query func(env *testEnv) (interface{}, error)
+	}{
+		{"GetAllElections", func(env *testEnv) (interface{}, error) {
+			return env.contract.GetAllElections(env.ctx, true)
+		}},
+		{"GetAllCandidates", func(env *testEnv) (interface{}, error) {
+			return env.contract.GetAllCandidates(env.ctx)
+		}},
+		{"getAllVoters", func(env *testEnv) (interface{}, error) {
+			return env.contract.getAllVoters(env.ctx)
+		}},
+		{"GetUnassignedCandidates", func(env *testEnv) (interface{}, error) {
+			return env.contract.GetUnassignedCandidates(env.ctx)
+		}},
+		{"GetElectionsModifiedSince", func(env *testEnv) (interface{}, error) {
+			return env.contract.GetElectionsModifiedSince(env.ctx, testNow.Add(-time.Hour).Format(time.RFC3339))
+		}},
+		{"GetConstituenciesInUse", func(env *testEnv) (interface{}, error) {
+			return env.contract.GetConstituenciesInUse(env.ctx)
+		}},
+		{"FindOverlappingElections", func(env *testEnv) (interface{}, error) {
+			return env.contract.FindOverlappingElections(env.ctx, "North")
+		}},
+		{"GetCandidateActiveElections", func(env *testEnv) (interface{}, error) {
+			// The query needs a registered candidate, who stands nowhere yet
+			env.registerCandidates("North", "C1")
+			return env.contract.GetCandidateActiveElections(env.ctx, "C1")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			got, err := tt.query(env)
+			must(t, err)
+
+			gotJSON, err := json.Marshal(got)
+			must(t, err)
+			if string(gotJSON) != "[]" {
+				t.Errorf("%s on an empty ledger marshals as %s, want []", tt.name, gotJSON)
+			}
+		})
+	}
+}
+
+func TestCandidateIDsTrimmed(t *testing.T) {
+	tests := []struct {
+		name        string
+		candidates  string
+		candidateID string
+		want        string
+	}{
+		{"padded election candidates", `[" C1 ","C2\t"]`, "C1", ""},
+		{"padded vote", `["C1","C2"]`, "  C1\n", ""},
+		{"both padded", `[" C1","C2"]`, "C1 ", ""},
+		{"empty candidate ID", `["C1","  "]`, "C1", "candidate IDs must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.registerCandidates("North", "C1", "C2")
+			env.registerVoters("North", "V1")
+			err := env.contract.CreateElection(env.ctx, "E1", "Election E1", "", env.now.Add(-time.Hour).Format(time.RFC3339), env.now.Add(time.Hour).Format(time.RFC3339), tt.candidates)
+			if tt.want != "" {
+				mustFail(t, err, tt.want)
+				return
+			}
+			must(t, err)
+			if got := strings.Join(env.election("E1").Candidates, ","); got != "C1,C2" {
+				t.Errorf("candidates = %q, want C1,C2", got)
+			}
+			env.setStatus("E1", "active")
+
+			env.mustVote("E1", "V1", tt.candidateID)
+			env.endElection("E1")
+			if votes := candidateVotes(env.results("E1")); votes["C1"] != 1 {
+				t.Errorf("votes = %v, want one for C1", votes)
+			}
+		})
+	}
+}